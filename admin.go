@@ -0,0 +1,135 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"errors"
+	"github.com/go-ceres/go-ceres/logger"
+	"github.com/go-ceres/go-ceres/server"
+	"github.com/go-ceres/go-ceres/server/admin"
+	"net/http"
+	"reflect"
+)
+
+var (
+	// errNotReady 服务未就绪
+	errNotReady = errors.New("engine is not ready")
+	// errHealthDown 健康检查未通过
+	errHealthDown = errors.New("health check failed")
+)
+
+// serverRoutes 服务路由信息
+type serverRoutes struct {
+	Name    string         `json:"name"`
+	Scheme  string         `json:"scheme"`
+	Address string         `json:"address"`
+	Routes  []server.Route `json:"routes"`
+}
+
+// jobEntry 定时任务信息
+type jobEntry struct {
+	Id   int    `json:"id"`
+	Job  string `json:"job"`
+	Prev string `json:"prev"`
+	Next string `json:"next"`
+}
+
+// initAdmin 初始化管理服务
+func (eng *Engine) initAdmin() error {
+	// 自定义子命令不启动管理服务
+	if eng.subcommand != nil || !eng.conf.Get("ceres.admin.enable").Bool(false) {
+		return nil
+	}
+	eng.admin = admin.ScanConfigContext(eng.Context()).WithLogger(eng.logger.With(logger.FieldMod("server.admin"))).Build()
+	eng.admin.SetLiveness(eng.liveness)
+	eng.admin.SetReadiness(eng.readiness)
+	eng.admin.HandleJSON("/routes", eng.adminRoutes)
+	eng.admin.HandleJSON("/jobs", eng.adminJobs)
+	eng.admin.HandleJSON("/registry", eng.adminRegistry)
+	eng.admin.HandleJSON("/workers", eng.adminWorkers)
+	return nil
+}
+
+// Admin 获取管理服务，未开启时返回nil
+func (eng *Engine) Admin() *admin.Server {
+	return eng.admin
+}
+
+// adminRoutes 所有服务的路由
+func (eng *Engine) adminRoutes(_ *http.Request) (interface{}, error) {
+	eng.rw.RLock()
+	servers := make([]server.Server, len(eng.servers))
+	copy(servers, eng.servers)
+	eng.rw.RUnlock()
+	res := make([]serverRoutes, 0, len(servers))
+	for _, s := range servers {
+		lister, ok := s.(server.RouteLister)
+		if !ok {
+			continue
+		}
+		info := s.Info()
+		res = append(res, serverRoutes{
+			Name:    info.Name,
+			Scheme:  info.Scheme,
+			Address: info.Address,
+			Routes:  lister.ListRoutes(),
+		})
+	}
+	return res, nil
+}
+
+// adminJobs 定时任务列表
+func (eng *Engine) adminJobs(_ *http.Request) (interface{}, error) {
+	entries := eng.schedule.List()
+	res := make([]jobEntry, 0, len(entries))
+	for _, entry := range entries {
+		job := jobEntry{
+			Id:  int(entry.ID),
+			Job: reflect.TypeOf(entry.Job).String(),
+		}
+		if !entry.Prev.IsZero() {
+			job.Prev = entry.Prev.Format("2006-01-02 15:04:05")
+		}
+		if !entry.Next.IsZero() {
+			job.Next = entry.Next.Format("2006-01-02 15:04:05")
+		}
+		res = append(res, job)
+	}
+	return res, nil
+}
+
+// adminRegistry 当前实例注册到注册中心的服务
+func (eng *Engine) adminRegistry(_ *http.Request) (interface{}, error) {
+	eng.rw.RLock()
+	defer eng.rw.RUnlock()
+	res := make([]*server.ServiceInfo, 0, len(eng.registered))
+	for _, info := range eng.registered {
+		res = append(res, info)
+	}
+	registryName := ""
+	if eng.registry != nil {
+		registryName = eng.registry.String()
+	}
+	return map[string]interface{}{
+		"registry": registryName,
+		"services": res,
+	}, nil
+}
+
+// adminWorkers 后台任务状态
+func (eng *Engine) adminWorkers(_ *http.Request) (interface{}, error) {
+	return eng.Workers(), nil
+}
@@ -16,6 +16,7 @@
 package token
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
 	"github.com/go-ceres/go-ceres/logger"
@@ -23,20 +24,25 @@ import (
 
 // Config 配置信息
 type Config struct {
-	TokenName       string     `json:"token_name" yaml:"TokenName" toml:"token_name"`                   // token名称，提交的时候按照此名称获取token数据
-	Timeout         int64      `json:"timeout" yaml:"Timeout" toml:"timeout"`                           // userToken 过期时间
-	ActivityTimeout int64      `json:"activity_timeout" yaml:"ActivityTimeout" toml:"activity_timeout"` // 临时过期时间，用于（超过多少时间不能再操作）
-	IsConcurrent    bool       `json:"is_concurrent" yaml:"IsConcurrent" toml:"is_concurrent"`          // 是否支持多账号登录
-	IsShare         bool       `json:"is_share" yaml:"IsShare" toml:"is_share"`                         // 是否共享token
-	TokenStyle      TokenStyle `json:"token_style" yaml:"TokenStyle" toml:"token_style"`                // token的样式
-	AutoRenew       bool       `json:"auto_renew" yaml:"AutoRenew"  toml:"auto_renew"`                  // 自动续签
-	TokenPrefix     string     `json:"token_prefix" yaml:"TokenPrefix" toml:"token_prefix"`             // token前缀
-	IsLog           bool       `json:"is_log" yaml:"IsLog" toml:"is_log"`                               // 是否打印日志
-	CheckLogin      bool       `json:"check_login" yaml:"CheckLogin" toml:"check_login"`                // 检查是否登录
+	TokenName       string     `json:"token_name" yaml:"TokenName" toml:"token_name" usage:"token名称，提交的时候按照此名称获取token数据"`
+	Timeout         int64      `json:"timeout" yaml:"Timeout" toml:"timeout" usage:"userToken 过期时间"`
+	ActivityTimeout int64      `json:"activity_timeout" yaml:"ActivityTimeout" toml:"activity_timeout" usage:"临时过期时间，用于（超过多少时间不能再操作）"`
+	IsConcurrent    bool       `json:"is_concurrent" yaml:"IsConcurrent" toml:"is_concurrent" usage:"是否支持多账号登录"`
+	IsShare         bool       `json:"is_share" yaml:"IsShare" toml:"is_share" usage:"是否共享token"`
+	TokenStyle      TokenStyle `json:"token_style" yaml:"TokenStyle" toml:"token_style" usage:"token的样式"`
+	AutoRenew       bool       `json:"auto_renew" yaml:"AutoRenew"  toml:"auto_renew" usage:"自动续签"`
+	TokenPrefix     string     `json:"token_prefix" yaml:"TokenPrefix" toml:"token_prefix" usage:"token前缀"`
+	IsLog           bool       `json:"is_log" yaml:"IsLog" toml:"is_log" usage:"是否打印日志"`
+	CheckLogin      bool       `json:"check_login" yaml:"CheckLogin" toml:"check_login" usage:"检查是否登录"`
 	logger          Logger     // 日志组件
 }
 
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		TokenName:       "ceres-token",
 		Timeout:         2592000,
@@ -48,14 +54,19 @@ func DefaultConfig() *Config {
 		TokenPrefix:     "Bearer",
 		IsLog:           true,
 		CheckLogin:      true,
-		logger:          logger.FrameLogger.With(logger.FieldMod(errors.ModAuthToken)),
+		logger:          logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModAuthToken)),
 	}
 }
 
 // RawConfig 根据配置键扫描配置
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	err := config.Get(key).Scan(conf)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(conf)
 	if err != nil {
 		conf.logger.Panicd("parse config", logger.FieldErr(err), logger.FieldAny("key", key), logger.FieldValue(conf))
 	}
@@ -64,7 +75,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 根据配置名扫描配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.auth.token." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.auth.token."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.auth.token",
+		Named:       true,
+		Description: "token authentication",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithLogger 设置日志组件
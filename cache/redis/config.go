@@ -16,6 +16,7 @@
 package redis
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/cache"
 	"github.com/go-ceres/go-ceres/client/redis"
 	"github.com/go-ceres/go-ceres/config"
@@ -25,26 +26,36 @@ import (
 
 // Config 配置信息
 type Config struct {
-	Prefix string `json:"prefix"`
-	Type   string `json:"type"`
+	Prefix string `json:"prefix" usage:"缓存key前缀"`
+	Type   string `json:"type" usage:"缓存类型"`
 	*redis.Config
 	logger logger.Interface
 }
 
 // DefaultConfig 默认配置文件
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Prefix: "ceres:cache",
 		Type:   "redis",
-		Config: redis.DefaultConfig(),
-		logger: logger.FrameLogger.With(logger.FieldMod(errors.ModCacheRedis)),
+		Config: redis.DefaultConfig().WithLogger(logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModClientRedis))),
+		logger: logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModCacheRedis)),
 	}
 }
 
 // RawConfig ...
 func RawConfig(key string) *Config {
-	c := DefaultConfig()
-	err := config.Get(key).Scan(c)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	c := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(c)
 	if err != nil {
 		c.logger.DPanicf("parse config", logger.FieldErr(err), logger.FieldAny("key", key), logger.FieldValue(c))
 	}
@@ -53,7 +64,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 扫描配置文件
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.cache." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.cache."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.cache",
+		Named:       true,
+		Description: "redis cache",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // Build 构建缓存组件
@@ -2,13 +2,16 @@ package ceres
 
 import (
 	"context"
+	"github.com/go-ceres/cli/v2"
 	"github.com/go-ceres/go-ceres/cmd"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/health"
 	"github.com/go-ceres/go-ceres/logger"
 	"github.com/go-ceres/go-ceres/registry"
 	"github.com/go-ceres/go-ceres/schedule"
 	"github.com/go-ceres/go-ceres/server"
+	"github.com/go-ceres/go-ceres/server/admin"
 	"github.com/go-ceres/go-ceres/utils/cyclex"
 	"github.com/go-ceres/go-ceres/utils/signalsx"
 	"go.uber.org/automaxprocs/maxprocs"
@@ -16,25 +19,52 @@ import (
 	"runtime"
 	"strconv"
 	"sync"
+	"time"
 )
 
 type Engine struct {
-	isSetup      bool               // 是否设置
-	init         []func() error     // 启动项方法
-	rw           *sync.RWMutex      // 读写锁
-	cycle        *cyclex.Cycle      // 异步运行管理
-	servers      []server.Server    // 服务
-	schedule     *schedule.Schedule // 定时任务管理
-	registry     registry.Registry  // 注册中心
-	logger       *logger.Logger     // 日志框架
-	beforeStarts []func() error     // 启动前回调
-	beforeStops  []func() error     // 停止前回调
-	afterStarts  []func() error     // 启动后回调
-	afterStops   []func() error     // 停止后回调
-	initOnce     sync.Once
-	setupOnce    sync.Once
-	stopOnce     sync.Once
-	clear        func() // 程序结束后回调
+	isSetup       bool                                  // 是否设置
+	init          []func() error                        // 启动项方法
+	rw            *sync.RWMutex                         // 读写锁
+	ctx           context.Context                       // 运行上下文，停止时取消
+	cancel        context.CancelFunc                    // 取消运行上下文
+	cycle         *cyclex.Cycle                         // 异步运行管理
+	servers       []server.Server                       // 服务
+	workers       []*worker                             // 后台任务
+	workerStarted bool                                  // 后台任务是否已启动
+	infos         map[server.Server]*server.ServiceInfo // 需要注册到注册中心的服务信息
+	registered    map[server.Server]*server.ServiceInfo // 已注册到注册中心的服务信息
+	admin         *admin.Server                         // 管理服务
+	health        *health.Health                        // 健康检查
+	healthEvery   time.Duration                         // 健康检查间隔
+	unwatchHealth func()                                // 取消健康状态变化监听
+	stopSignals   []func()                              // 取消信号监听
+	ready         bool                                  // 是否已就绪
+	stopping      bool                                  // 是否正在停止
+	upgrading     int32                                 // 是否正在平滑升级
+	schedule      *schedule.Schedule                    // 定时任务管理
+	registry      registry.Registry                     // 注册中心
+	elector       Elector                               // 选主
+	leaders       []LeaderFunc                          // 成为leader后运行的方法
+	leader        bool                                  // 是否为leader
+	logger        *logger.Logger                        // 日志框架
+	conf          config.Config                         // 配置管理器，默认为全局配置
+	command       *cmd.Command                          // 命令行，默认为全局命令行
+	frameLogger   *logger.Logger                        // 框架日志，默认为全局框架日志
+	defaultLogger *logger.Logger                        // 项目日志，默认为全局项目日志
+	withoutCmd    bool                                  // 是否跳过命令行解析和插件初始化
+	commands      []*cli.Command                        // 自定义子命令
+	subcommand    *subcommand                           // 命令行选中的自定义子命令
+	beforeStarts  []*Hook                               // 启动前回调
+	beforeStops   []*Hook                               // 停止前回调
+	afterStarts   []*Hook                               // 启动后回调
+	afterStops    []*Hook                               // 停止后回调
+	stopDelay     time.Duration                         // 注销服务后等待注册中心传播的时间
+	stopTimeout   time.Duration                         // 收到退出信号后优雅停止的超时时间
+	initOnce      sync.Once
+	setupOnce     sync.Once
+	stopOnce      sync.Once
+	clear         func() // 程序结束后回调
 }
 
 // New 创建一个启动器
@@ -50,13 +80,27 @@ func New(fns ...func() (func(), error)) (*Engine, error) {
 func (eng *Engine) initialize() {
 	eng.initOnce.Do(func() {
 		eng.rw = &sync.RWMutex{}
+		eng.ctx, eng.cancel = context.WithCancel(context.Background())
+		if eng.conf == nil {
+			eng.conf = config.DefaultConfig
+		}
+		if eng.command == nil {
+			eng.command = cmd.DefaultCmd
+		}
 		eng.cycle = cyclex.NewCycle()
 		eng.servers = make([]server.Server, 0)
-		eng.beforeStarts = make([]func() error, 0)
-		eng.beforeStops = make([]func() error, 0)
-		eng.afterStarts = make([]func() error, 0)
-		eng.afterStops = make([]func() error, 0)
-		eng.logger = logger.FrameLogger.With(logger.FieldMod("app"))
+		eng.workers = make([]*worker, 0)
+		eng.infos = make(map[server.Server]*server.ServiceInfo)
+		eng.registered = make(map[server.Server]*server.ServiceInfo)
+		if eng.health == nil {
+			eng.health = health.DefaultHealth
+		}
+		eng.beforeStarts = make([]*Hook, 0)
+		eng.beforeStops = make([]*Hook, 0)
+		eng.afterStarts = make([]*Hook, 0)
+		eng.afterStops = make([]*Hook, 0)
+		eng.clear = func() {}
+		eng.logger = logger.FrameFromContext(eng.Context()).With(logger.FieldMod("app"))
 	})
 }
 
@@ -66,8 +110,12 @@ func (eng *Engine) setup() (err error) {
 		eng.initCmd,
 		eng.printBanner,
 		eng.initLogger,
+		eng.initConfig,
 		eng.initMaxProcs,
+		eng.initStop,
 		eng.initCron,
+		eng.initHealth,
+		eng.initAdmin,
 	}
 	init = append(init, eng.init...)
 	eng.setupOnce.Do(func() {
@@ -79,15 +127,19 @@ func (eng *Engine) setup() (err error) {
 
 // initCmd 初始化命令行
 func (eng *Engine) initCmd() error {
+	if eng.withoutCmd {
+		return nil
+	}
 	var opts []cmd.Option
 	// 初始化插件命令行
-	cmd.DefaultPluginManager.Range(func(n string, p cmd.Plugin) bool {
+	eng.command.Plugins().Range(func(n string, p cmd.Plugin) bool {
 		// 获取该插件的命令行
 		flags := p.Flags()
 		opts = append(opts, cmd.WithFlags(flags))
 		return true
 	})
-	return cmd.DefaultCmd.Init(opts...)
+	eng.command.AddCommand(eng.commands...)
+	return eng.command.InitContext(eng.Context(), opts...)
 }
 
 // SetInit 设置启动项
@@ -103,7 +155,12 @@ func (eng *Engine) MustSetup(fns ...func() (func(), error)) error {
 	if err != nil {
 		return err
 	}
-	return eng.parallelUntilError(fns...)
+	if err := eng.parallelUntilError(fns...); err != nil {
+		return err
+	}
+	// 运行自定义子命令后退出，不会启动服务
+	eng.runCommand()
+	return nil
 }
 
 // Server 设置服务
@@ -138,23 +195,33 @@ func (eng *Engine) SetRegistry(registry registry.Registry) {
 // Run 运行
 func (eng *Engine) Run() error {
 	// 启动前回调
-	for _, fn := range eng.beforeStarts {
-		if err := fn(); err != nil {
-			return err
-		}
+	if err := eng.runStartHooks(context.Background(), stageBeforeStart, &eng.beforeStarts); err != nil {
+		return err
+	}
+	// 参与选主
+	if err := eng.startElection(); err != nil {
+		return err
 	}
 	// 等待退出信号
 	eng.waitSignals()
+	// 等待平滑升级信号
+	eng.waitUpgrade()
 	// 启动服务
 	eng.cycle.Run(eng.startServer)
+	// 定时健康检查
+	eng.cycle.Run(eng.watchHealth)
 	// 启动定时任务
 	eng.cycle.Run(eng.startCron)
-	// 启动后回调
-	for _, fn := range eng.afterStarts {
-		if err := fn(); err != nil {
-			return err
-		}
+	// 启动后台任务
+	eng.startWorkers()
+	// 启动后回调，失败则停止已启动的服务
+	if err := eng.runStartHooks(context.Background(), stageAfterStart, &eng.afterStarts); err != nil {
+		_ = eng.Stop()
+		return err
 	}
+	eng.setReady(true)
+	// 通知父进程已就绪
+	eng.notifyUpgradeReady()
 	// 阻止并等待退出
 	if err := <-eng.cycle.Wait(); err != nil {
 		eng.logger.Error("ceres shutdown with error", logger.FieldMod(errors.ModApp), logger.FieldErr(err))
@@ -168,9 +235,9 @@ func (eng *Engine) Run() error {
 func (eng *Engine) Stop() (err error) {
 	eng.stopOnce.Do(func() {
 		// 关闭前回调
-		for _, fn := range eng.beforeStops {
-			_ = fn()
-		}
+		eng.markStopping()
+		eng.stopHealth()
+		_ = eng.runStopHooks(context.Background(), stageBeforeStop, &eng.beforeStops)
 		if eng.registry != nil {
 			err = eng.registry.Close()
 			if err != nil {
@@ -178,20 +245,95 @@ func (eng *Engine) Stop() (err error) {
 			}
 		}
 		//stop servers
-		eng.rw.RLock()
-		for _, s := range eng.servers {
+		for _, s := range eng.allServers() {
 			func(s server.Server) {
 				eng.cycle.Run(s.Stop)
 			}(s)
 		}
-		eng.rw.RUnlock()
+		// 停止定时任务
+		eng.stopCron()
+		// 取消运行上下文
+		eng.cancel()
+
+		<-eng.cycle.Done()
+		// 关闭后回调
+		_ = eng.runStopHooks(context.Background(), stageAfterStop, &eng.afterStops)
+		// 销毁插件
+		if !eng.withoutCmd {
+			eng.command.Plugins().Destroy()
+		}
+
+		// 取消信号监听
+		eng.stopSignal()
+
+		eng.clear()
+
+		eng.cycle.Close()
+	})
+	return
+}
+
+// GracefulStop 优雅地停止
+// 先从注册中心注销所有服务，等待注销信息传播后再优雅停止各个服务，
+// ctx结束时仍未停止的服务将被强制停止
+func (eng *Engine) GracefulStop(ctx context.Context) (err error) {
+	eng.stopOnce.Do(func() {
+		// 关闭前回调
+		eng.markStopping()
+		eng.stopHealth()
+		eng.drainServers()
+		_ = eng.runStopHooks(ctx, stageBeforeStop, &eng.beforeStops)
+		// 注销服务，避免新的流量进入
+		eng.deregisterServers()
+		// 等待注册中心将注销信息传播到调用方
+		if eng.registry != nil && eng.stopDelay > 0 {
+			eng.logger.Infod("wait for deregister propagation", logger.FieldMod(errors.ModApp), logger.FieldAny("delay", eng.stopDelay.String()))
+			timer := time.NewTimer(eng.stopDelay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+			}
+		}
+		if eng.registry != nil {
+			if closeErr := eng.registry.Close(); closeErr != nil {
+				eng.logger.Error("stop registry close err", logger.FieldMod(errors.ModApp), logger.FieldErr(closeErr))
+			}
+		}
+		// 优雅停止服务
+		servers := eng.allServers()
+		var eg errgroup.Group
+		for _, s := range servers {
+			s := s
+			eg.Go(func() error {
+				return s.GracefulStop(ctx)
+			})
+		}
+		if err = eg.Wait(); err != nil {
+			// 超时或出错，强制停止
+			eng.logger.Errord("graceful stop server, fallback to stop", logger.FieldMod(errors.ModApp), logger.FieldErr(err))
+			for _, s := range servers {
+				if stopErr := s.Stop(); stopErr != nil {
+					eng.logger.Errord("stop server", logger.FieldMod(errors.ModApp), logger.FieldErr(stopErr), logger.FieldValue(s.Info()))
+				}
+			}
+		}
+		// 停止定时任务
+		eng.stopCron()
+		// 取消运行上下文
+		eng.cancel()
 
 		<-eng.cycle.Done()
 		// 关闭后回调
-		for _, fn := range eng.afterStops {
-			_ = fn()
+		_ = eng.runStopHooks(context.Background(), stageAfterStop, &eng.afterStops)
+		// 销毁插件
+		if !eng.withoutCmd {
+			eng.command.Plugins().Destroy()
 		}
 
+		// 取消信号监听
+		eng.stopSignal()
+
 		eng.clear()
 
 		eng.cycle.Close()
@@ -234,6 +376,9 @@ func (eng *Engine) serialUntilError(fns ...func() error) error {
 
 // printBanner 打印banner
 func (eng *Engine) printBanner() error {
+	if eng.subcommand != nil {
+		return nil
+	}
 	// 如果自定义了banner
 	if customBanner != "" {
 		printBanner(customBanner)
@@ -244,23 +389,32 @@ func (eng *Engine) printBanner() error {
 	return nil
 }
 
-// initLogger 初始化日志
+// initLogger 初始化日志，通过选项指定的日志不会被配置覆盖
 func (eng *Engine) initLogger() error {
+	ctx := eng.Context()
+	// 使用全局配置时同时更新全局日志，保持原有行为
+	global := eng.conf == config.DefaultConfig
 	// 框架日志
-	if !config.Get("ceres.logger.frame").IsEmpty() {
-		logger.FrameLogger = logger.ScanConfig("frame").Build()
+	if eng.frameLogger == nil && !eng.conf.Get("ceres.logger.frame").IsEmpty() {
+		eng.frameLogger = logger.ScanConfigContext(ctx, "frame").Build()
+		if global {
+			logger.FrameLogger = eng.frameLogger
+		}
 	}
 	// 项目日志
-	if !config.Get("ceres.logger.default").IsEmpty() {
-		logger.DefaultLogger = logger.ScanConfig("default").Build()
+	if eng.defaultLogger == nil && !eng.conf.Get("ceres.logger.default").IsEmpty() {
+		eng.defaultLogger = logger.ScanConfigContext(ctx, "default").Build()
+		if global {
+			logger.DefaultLogger = eng.defaultLogger
+		}
 	}
-	eng.logger = logger.FrameLogger.With(logger.FieldMod(errors.ModApp))
+	eng.logger = logger.FrameFromContext(eng.Context()).With(logger.FieldMod(errors.ModApp))
 	return nil
 }
 
 // initMaxProcs 初始化MaxProcs
 func (eng *Engine) initMaxProcs() error {
-	if maxProcs := config.Get("ceres.application.maxProc").Int(0); maxProcs != 0 {
+	if maxProcs := eng.conf.Get("ceres.application.maxProc").Int(0); maxProcs != 0 {
 		runtime.GOMAXPROCS(maxProcs)
 	} else {
 		if _, err := maxprocs.Set(); err != nil {
@@ -270,41 +424,70 @@ func (eng *Engine) initMaxProcs() error {
 	return nil
 }
 
+// initStop 初始化停止相关配置
+func (eng *Engine) initStop() error {
+	eng.stopDelay = eng.conf.Get("ceres.application.stopDelay").Duration(0)
+	eng.stopTimeout = eng.conf.Get("ceres.application.stopTimeout").Duration(30 * time.Second)
+	return nil
+}
+
 // initCron 初始化定时任务管理
 func (eng *Engine) initCron() error {
-	eng.schedule = schedule.ScanConfig("default").WithLogger(&schedule.Logger{Log: eng.logger.AddCallerSkip(1).With(logger.FieldMod("schedule"))}).Build()
+	eng.schedule = schedule.ScanConfigContext(eng.Context(), "default").WithLogger(&schedule.Logger{Log: eng.logger.AddCallerSkip(1).With(logger.FieldMod("schedule"))}).Build()
 	return nil
 }
 
 // waitSignals 等待退出信号
 func (eng *Engine) waitSignals() {
 	eng.logger.Infod("init listen signal", logger.FieldMod(errors.ModApp))
-	signalsx.Shutdown(func(grace bool) { //when get shutdown signal
-		//todo: support timeout
+	eng.addSignal(signalsx.Shutdown(func(grace bool) { //when get shutdown signal
 		if grace {
-			_ = eng.GracefulStop(context.TODO())
+			ctx, cancel := context.WithTimeout(context.Background(), eng.stopTimeout)
+			defer cancel()
+			_ = eng.GracefulStop(ctx)
 		} else {
 			_ = eng.Stop()
 		}
-	})
+	}))
+}
+
+// addSignal 记录信号监听，停止后取消
+func (eng *Engine) addSignal(stop func()) {
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	eng.stopSignals = append(eng.stopSignals, stop)
+}
+
+// stopSignal 取消所有信号监听，停止完成后调用，停止过程中再次收到信号仍可强制退出
+func (eng *Engine) stopSignal() {
+	eng.rw.Lock()
+	stops := eng.stopSignals
+	eng.stopSignals = nil
+	eng.rw.Unlock()
+	for _, stop := range stops {
+		stop()
+	}
 }
 
 // startServer 启动服务
 func (eng *Engine) startServer() error {
 	var eg errgroup.Group
 	// start multi servers
-	for _, s := range eng.servers {
+	for _, s := range eng.allServers() {
 		s := s
 		eg.Go(func() (err error) {
-			// 如果有注册中心,则注册服务
-			if eng.registry != nil {
-				err = eng.registerServer(s.Info())
-				defer func(eng *Engine, info *server.ServiceInfo) {
-					err = eng.unRegisterServer(info)
-					if err != nil {
-						eng.logger.Errord("unregister service", logger.FieldMod(errors.ModApp))
+			// 如果有注册中心,则注册服务，管理服务不注册
+			if eng.registry != nil && s != server.Server(eng.admin) {
+				eng.rw.Lock()
+				eng.infos[s] = s.Info()
+				eng.rw.Unlock()
+				// 健康检查未通过时暂不注册，恢复后再注册
+				if eng.isHealthy() {
+					if err = eng.registerNode(s); err != nil {
+						return
 					}
-				}(eng, s.Info())
+				}
+				defer eng.forgetServer(s)
 			}
 			eng.logger.Infod("start server", logger.FieldMod(errors.ModApp), logger.FieldValue(s.Info()))
 			defer eng.logger.Infod("exit server", logger.FieldMod(errors.ModApp), logger.FieldValue(s.Info()))
@@ -315,6 +498,115 @@ func (eng *Engine) startServer() error {
 	return eg.Wait()
 }
 
+// allServers 获取所有需要运行的服务，包括管理服务
+func (eng *Engine) allServers() []server.Server {
+	eng.rw.RLock()
+	defer eng.rw.RUnlock()
+	servers := make([]server.Server, 0, len(eng.servers)+1)
+	servers = append(servers, eng.servers...)
+	if eng.admin != nil {
+		servers = append(servers, eng.admin)
+	}
+	return servers
+}
+
+// setReady 设置就绪状态
+func (eng *Engine) setReady(ready bool) {
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	eng.ready = ready
+}
+
+// markStopping 标记为正在停止
+func (eng *Engine) markStopping() {
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	eng.ready = false
+	eng.stopping = true
+}
+
+// isStopping 是否正在停止
+func (eng *Engine) isStopping() bool {
+	eng.rw.RLock()
+	defer eng.rw.RUnlock()
+	return eng.stopping
+}
+
+// isReady 是否已就绪
+func (eng *Engine) isReady() bool {
+	eng.rw.RLock()
+	defer eng.rw.RUnlock()
+	return eng.ready
+}
+
+// registerNode 将服务注册到注册中心，已注册的服务直接忽略
+func (eng *Engine) registerNode(s server.Server) error {
+	eng.rw.RLock()
+	info, ok := eng.infos[s]
+	_, registered := eng.registered[s]
+	eng.rw.RUnlock()
+	if !ok || registered {
+		return nil
+	}
+	if err := eng.registerServer(info); err != nil {
+		return err
+	}
+	eng.rw.Lock()
+	eng.registered[s] = info
+	eng.rw.Unlock()
+	return nil
+}
+
+// registerServers 将所有未注册的服务注册到注册中心
+func (eng *Engine) registerServers() {
+	eng.rw.RLock()
+	servers := make([]server.Server, 0, len(eng.infos))
+	for s := range eng.infos {
+		servers = append(servers, s)
+	}
+	eng.rw.RUnlock()
+	for _, s := range servers {
+		if err := eng.registerNode(s); err != nil {
+			eng.logger.Errord("register service", logger.FieldMod(errors.ModApp), logger.FieldErr(err))
+		}
+	}
+}
+
+// forgetServer 服务退出后注销并不再注册
+func (eng *Engine) forgetServer(s server.Server) {
+	eng.rw.Lock()
+	delete(eng.infos, s)
+	eng.rw.Unlock()
+	eng.deregisterServer(s)
+}
+
+// deregisterServers 从注册中心注销所有已注册的服务
+func (eng *Engine) deregisterServers() {
+	eng.rw.RLock()
+	servers := make([]server.Server, 0, len(eng.registered))
+	for s := range eng.registered {
+		servers = append(servers, s)
+	}
+	eng.rw.RUnlock()
+	for _, s := range servers {
+		eng.deregisterServer(s)
+	}
+}
+
+// deregisterServer 从注册中心注销服务，未注册的服务直接忽略
+func (eng *Engine) deregisterServer(s server.Server) {
+	eng.rw.Lock()
+	info, ok := eng.registered[s]
+	delete(eng.registered, s)
+	eng.rw.Unlock()
+	if !ok {
+		return
+	}
+	if err := eng.unRegisterServer(info); err != nil {
+		eng.logger.Errord("unregister service", logger.FieldMod(errors.ModApp), logger.FieldErr(err), logger.FieldValue(info))
+	}
+}
+
 // registerServer 注册服务
 func (eng *Engine) registerServer(info *server.ServiceInfo) error {
 	srv := &registry.Service{
@@ -357,8 +649,9 @@ func (eng *Engine) startCron() error {
 	return nil
 }
 
-// GracefulStop 优雅地停止
-func (eng *Engine) GracefulStop(todo context.Context) error {
-
-	return nil
+// stopCron 停止定时任务管理
+func (eng *Engine) stopCron() {
+	if eng.schedule != nil {
+		eng.schedule.Stop()
+	}
 }
@@ -16,6 +16,7 @@
 package etcd
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
 	"github.com/go-ceres/go-ceres/logger"
@@ -25,31 +26,36 @@ import (
 
 // Config 配置信息
 type Config struct {
-	Endpoints            []string      `json:"endpoints"`
-	AutoSyncInterval     time.Duration `json:"auto_sync_interval"`
-	DialTimeout          time.Duration `json:"dial_timeout"`
-	DialKeepAliveTime    time.Duration `json:"dial_keep_alive_time"`
-	DialKeepAliveTimeout time.Duration `json:"dial_keep_alive_timeout"`
-	CertFile             string        `json:"cert_file"`
-	KeyFile              string        `json:"key_file"`
-	CaCert               string        `json:"ca_cert"`
-	Username             string        `json:"username"`
-	Password             string        `json:"password"`
-	RejectOldCluster     bool          `json:"reject_old_cluster"`
-	PermitWithoutStream  bool          `json:"permit_without_stream"`
-	Secure               bool          `json:"secure"`
+	Endpoints            []string      `json:"endpoints" usage:"连接地址"`
+	AutoSyncInterval     time.Duration `json:"auto_sync_interval" usage:"自动同步成员列表的间隔"`
+	DialTimeout          time.Duration `json:"dial_timeout" usage:"连接超时时间"`
+	DialKeepAliveTime    time.Duration `json:"dial_keep_alive_time" usage:"保活探测间隔"`
+	DialKeepAliveTimeout time.Duration `json:"dial_keep_alive_timeout" usage:"保活探测超时时间"`
+	CertFile             string        `json:"cert_file" usage:"tls的cert文件路径"`
+	KeyFile              string        `json:"key_file" usage:"tls的key文件路径"`
+	CaCert               string        `json:"ca_cert" usage:"tls的ca文件路径"`
+	Username             string        `json:"username" usage:"用户名"`
+	Password             string        `json:"password" usage:"密码"`
+	RejectOldCluster     bool          `json:"reject_old_cluster" usage:"是否拒绝连接过时的集群"`
+	PermitWithoutStream  bool          `json:"permit_without_stream" usage:"没有活动流时是否允许保活探测"`
+	Secure               bool          `json:"secure" usage:"是否使用tls连接"`
 	logger               *logger.Logger
 	DialOptions          []grpc.DialOption
 }
 
 // DefaultConfig 默认的配置
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Endpoints:            []string{"127.0.0.1:2379"},
 		DialTimeout:          time.Second * 5,
 		DialKeepAliveTime:    time.Second * 10,
 		DialKeepAliveTimeout: time.Second * 3,
-		logger:               logger.FrameLogger.With(logger.FieldMod(errors.ModClientEtcd)),
+		logger:               logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModClientEtcd)),
 		DialOptions: []grpc.DialOption{
 			grpc.WithBlock(),
 		},
@@ -58,8 +64,13 @@ func DefaultConfig() *Config {
 
 // RawConfig 扫描配置
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	err := config.Get(key).Scan(conf)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(conf)
 	if err != nil {
 		conf.logger.Panicd("etcd client parse config panic", logger.FieldErr(err), logger.FieldValue(conf))
 	}
@@ -68,7 +79,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 扫描配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.etcd." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.etcd."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.etcd",
+		Named:       true,
+		Description: "etcd client",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithLogger 单独设置日志组件
@@ -0,0 +1,181 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	"sync"
+)
+
+// Election 基于租约的选主
+type Election struct {
+	mu     sync.Mutex
+	client *Client
+	prefix string
+	opts   []concurrency.SessionOption
+	s      *concurrency.Session
+	e      *concurrency.Election
+	cancel context.CancelFunc // 取消当前任期的监听
+	lost   chan struct{}      // 当前任期结束时关闭
+}
+
+// NewElection 创建一个选主，所有使用相同前缀的实例参与同一个选举
+func (c *Client) NewElection(prefix string, opts ...concurrency.SessionOption) (*Election, error) {
+	el := &Election{
+		client: c,
+		prefix: prefix,
+		opts:   opts,
+		lost:   make(chan struct{}),
+	}
+	close(el.lost)
+	if err := el.renew(); err != nil {
+		return nil, err
+	}
+	return el, nil
+}
+
+// renew 创建新的会话
+func (el *Election) renew() error {
+	session, err := concurrency.NewSession(el.client.Client, el.opts...)
+	if err != nil {
+		return err
+	}
+	el.s = session
+	el.e = concurrency.NewElection(session, el.prefix)
+	return nil
+}
+
+// current 获取当前会话，会话过期后重新创建
+func (el *Election) current() (*concurrency.Session, *concurrency.Election, error) {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	select {
+	case <-el.s.Done():
+		if err := el.renew(); err != nil {
+			return nil, nil, err
+		}
+	default:
+	}
+	return el.s, el.e, nil
+}
+
+// Campaign 参与竞选，阻塞直到成为leader或ctx结束
+func (el *Election) Campaign(ctx context.Context, val string) error {
+	session, election, err := el.current()
+	if err != nil {
+		return err
+	}
+	if err := election.Campaign(ctx, val); err != nil {
+		return err
+	}
+	termCtx, cancel := context.WithCancel(context.Background())
+	lost := make(chan struct{})
+	el.mu.Lock()
+	el.cancel = cancel
+	el.lost = lost
+	el.mu.Unlock()
+	go el.watch(termCtx, session, election, lost)
+	return nil
+}
+
+// watch 监听当前任期，租约过期或leader变为其他实例时结束任期
+func (el *Election) watch(ctx context.Context, session *concurrency.Session, election *concurrency.Election, lost chan struct{}) {
+	defer close(lost)
+	key := election.Key()
+	observe := election.Observe(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-session.Done():
+			return
+		case resp, ok := <-observe:
+			if !ok {
+				return
+			}
+			if len(resp.Kvs) > 0 && string(resp.Kvs[0].Key) != key {
+				return
+			}
+		}
+	}
+}
+
+// Done 当前任期结束时关闭，未成为leader时返回已关闭的通道
+func (el *Election) Done() <-chan struct{} {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	return el.lost
+}
+
+// Resign 放弃leader身份
+func (el *Election) Resign(ctx context.Context) error {
+	el.mu.Lock()
+	election, cancel := el.e, el.cancel
+	el.cancel = nil
+	el.mu.Unlock()
+	if cancel != nil {
+		defer cancel()
+	}
+	return election.Resign(ctx)
+}
+
+// Leader 获取当前leader的值
+func (el *Election) Leader(ctx context.Context) (string, error) {
+	_, election, err := el.current()
+	if err != nil {
+		return "", err
+	}
+	resp, err := election.Leader(ctx)
+	if err != nil {
+		return "", err
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+// Observe 监听leader的变化，ctx结束时关闭通道
+func (el *Election) Observe(ctx context.Context) <-chan string {
+	res := make(chan string)
+	go func() {
+		defer close(res)
+		_, election, err := el.current()
+		if err != nil {
+			return
+		}
+		for resp := range election.Observe(ctx) {
+			if len(resp.Kvs) == 0 {
+				continue
+			}
+			select {
+			case res <- string(resp.Kvs[0].Value):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return res
+}
+
+// Close 关闭会话，会话关闭后租约撤销，leader身份随之失去
+func (el *Election) Close() error {
+	el.mu.Lock()
+	defer el.mu.Unlock()
+	if el.cancel != nil {
+		el.cancel()
+		el.cancel = nil
+	}
+	return el.s.Close()
+}
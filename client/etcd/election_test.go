@@ -0,0 +1,116 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"go.etcd.io/etcd/server/v3/embed"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// startEmbedEtcd 启动内嵌的etcd服务
+func startEmbedEtcd(t *testing.T) *Client {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	u, _ := url.Parse("http://127.0.0.1:0")
+	cfg.LCUrls = []url.URL{*u}
+	cfg.LPUrls = []url.URL{*u}
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		e.Close()
+		t.Fatal("embed etcd start timeout")
+	}
+	conf := DefaultConfig()
+	conf.Endpoints = []string{e.Clients[0].Addr().String()}
+	client := conf.Build()
+	t.Cleanup(func() {
+		_ = client.Close()
+		e.Close()
+	})
+	return client
+}
+
+func TestElection(t *testing.T) {
+	client := startEmbedEtcd(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	e1, err := client.NewElection("/ceres/election")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e1.Close()
+	e2, err := client.NewElection("/ceres/election")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e2.Close()
+
+	if err := e1.Campaign(ctx, "e1"); err != nil {
+		t.Fatal(err)
+	}
+	observe := e2.Observe(ctx)
+	if leader := <-observe; leader != "e1" {
+		t.Fatalf("leader = %s, want e1", leader)
+	}
+
+	elected := make(chan error, 1)
+	go func() {
+		elected <- e2.Campaign(ctx, "e2")
+	}()
+	select {
+	case <-elected:
+		t.Fatal("e2 elected while e1 is leader")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	if err := e1.Resign(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-e1.Done():
+	case <-time.After(time.Second):
+		t.Fatal("e1 term not ended after resign")
+	}
+	if err := <-elected; err != nil {
+		t.Fatal(err)
+	}
+	if leader := <-observe; leader != "e2" {
+		t.Fatalf("leader = %s, want e2", leader)
+	}
+	leader, err := e1.Leader(ctx)
+	if err != nil || leader != "e2" {
+		t.Fatalf("leader = %s, err = %v", leader, err)
+	}
+
+	// 会话关闭后失去leader身份
+	if err := e2.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-e2.Done():
+	case <-time.After(time.Second):
+		t.Fatal("e2 term not ended after close")
+	}
+}
@@ -0,0 +1,34 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package etcd
+
+import (
+	"context"
+	"github.com/go-ceres/go-ceres/health"
+)
+
+// HealthChecker etcd健康检查，任意一个节点状态正常即认为可用
+func (c *Client) HealthChecker() health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		var err error
+		for _, endpoint := range c.Endpoints() {
+			if _, err = c.Status(ctx, endpoint); err == nil {
+				return nil
+			}
+		}
+		return err
+	})
+}
@@ -16,6 +16,7 @@
 package grpc
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
 	"github.com/go-ceres/go-ceres/logger"
@@ -26,20 +27,25 @@ import (
 )
 
 type Config struct {
-	Debug         bool                        // 是否开启调试模式
-	Block         bool                        // 等待连接启动再返回
-	ReadTimeout   time.Duration               // 调用超时时间
-	DialTimeout   time.Duration               // 调用超时时间
-	SlowThreshold time.Duration               // 超时阈值
-	Balancer      string                      // 负载均衡策略
-	Secure        bool                        // 安全链接
-	KeepAlive     *keepalive.ClientParameters // 存活策略
+	Debug         bool                        `usage:"是否开启调试模式"`
+	Block         bool                        `usage:"等待连接启动再返回"`
+	ReadTimeout   time.Duration               `usage:"调用超时时间"`
+	DialTimeout   time.Duration               `usage:"调用超时时间"`
+	SlowThreshold time.Duration               `usage:"超时阈值"`
+	Balancer      string                      `usage:"负载均衡策略"`
+	Secure        bool                        `usage:"安全链接"`
+	KeepAlive     *keepalive.ClientParameters `usage:"存活策略"`
 	registry      registry.Registry           // 注册中心
 	logger        *logger.Logger              // 日志组件
 }
 
 // DefaultConfig 默认配置
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Block:         true,
 		ReadTimeout:   time.Second,
@@ -48,14 +54,19 @@ func DefaultConfig() *Config {
 		Balancer:      roundrobin.Name,
 		Debug:         true,
 		Secure:        false,
-		logger:        logger.FrameLogger.With(logger.FieldMod(errors.ModClientGrpc)),
+		logger:        logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModClientGrpc)),
 	}
 }
 
 // RawConfig 读取配置
 func RawConfig(key string) *Config {
-	c := DefaultConfig()
-	err := config.Get(key).Scan(c)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	c := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(c)
 	if err != nil {
 		c.logger.Panicd("parse config", logger.FieldErr(err), logger.FieldAny("key", key), logger.FieldValue(c))
 	}
@@ -64,7 +75,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 根据name扫描配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.client.grpc." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.client.grpc."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.client.grpc",
+		Named:       true,
+		Description: "grpc client",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithLogger 设置日志组件
@@ -16,6 +16,7 @@
 package redis
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
 	"github.com/go-ceres/go-ceres/logger"
@@ -31,23 +32,28 @@ const (
 )
 
 type Config struct {
-	Addrs        []string       `json:"addrs"`          // 连接地址
-	Mode         Mode           `json:"mode"`           // 模式（cluster,simple）
-	Password     string         `json:"password"`       // 密码
-	DB           int            `json:"db"`             // DB，默认为0, 一般应用不推荐使用DB分片
-	PoolSize     int            `json:"pool_size"`      // 集群内每个节点的最大连接池限制 默认每个CPU10个连接
-	MaxRetries   int            `json:"maxRetries"`     //网络相关的错误最大重试次数 默认5次
-	MinIdleConns int            `json:"min_idle_conns"` // 最小空闲连接数,默认100
-	DialTimeout  time.Duration  `json:"dial_timeout"`   // 连接超时
-	ReadTimeout  time.Duration  `json:"read_timeout"`   //读取超时 默认3s
-	WriteTimeout time.Duration  `json:"write_timeout"`  // 写入超时 默认3s
-	IdleTimeout  time.Duration  `json:"idle_timeout"`   // 连接最大空闲时间，默认60s, 超过该时间，连接会被主动关闭
-	Debug        bool           `json:"debug"`          // 是否开启debug模式
-	ReadOnly     bool           `json:"readOnly"`       // 集群模式中在从属节点上启用读模式
+	Addrs        []string       `json:"addrs" usage:"连接地址"`
+	Mode         Mode           `json:"mode" usage:"模式（cluster,simple）"`
+	Password     string         `json:"password" usage:"密码"`
+	DB           int            `json:"db" usage:"DB，默认为0, 一般应用不推荐使用DB分片"`
+	PoolSize     int            `json:"pool_size" usage:"集群内每个节点的最大连接池限制 默认每个CPU10个连接"`
+	MaxRetries   int            `json:"maxRetries" usage:"网络相关的错误最大重试次数 默认5次"`
+	MinIdleConns int            `json:"min_idle_conns" usage:"最小空闲连接数,默认100"`
+	DialTimeout  time.Duration  `json:"dial_timeout" usage:"连接超时"`
+	ReadTimeout  time.Duration  `json:"read_timeout" usage:"读取超时 默认3s"`
+	WriteTimeout time.Duration  `json:"write_timeout" usage:"写入超时 默认3s"`
+	IdleTimeout  time.Duration  `json:"idle_timeout" usage:"连接最大空闲时间，默认60s, 超过该时间，连接会被主动关闭"`
+	Debug        bool           `json:"debug" usage:"是否开启debug模式"`
+	ReadOnly     bool           `json:"readOnly" usage:"集群模式中在从属节点上启用读模式"`
 	logger       *logger.Logger // 日志组件
 }
 
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Addrs:        []string{"127.0.0.1:6379"},
 		DB:           0,
@@ -60,14 +66,19 @@ func DefaultConfig() *Config {
 		IdleTimeout:  time.Second * 60,
 		Debug:        false,
 		ReadOnly:     false,
-		logger:       logger.FrameLogger.With(logger.FieldMod(errors.ModClientRedis)),
+		logger:       logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModClientRedis)),
 	}
 }
 
 // RawConfig 根据key扫描配置
 func RawConfig(key string) *Config {
-	c := DefaultConfig()
-	err := config.Get(key).Scan(c)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	c := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(c)
 	if err != nil {
 		c.logger.Panicd("parse config", logger.FieldErr(err), logger.FieldAny("key", key), logger.FieldValue(c))
 	}
@@ -76,7 +87,33 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 根据名称扫描配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.client.redis." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.client.redis."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.client.redis",
+		Named:       true,
+		Description: "redis client",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
+}
+
+// WithLogger 设置日志组件
+func (c *Config) WithLogger(log *logger.Logger) *Config {
+	c.logger = log
+	return c
 }
 
 // buildClusterClient 构建redis集群客户端
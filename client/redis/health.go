@@ -0,0 +1,37 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package redis
+
+import (
+	"context"
+	"github.com/go-ceres/go-ceres/health"
+)
+
+// HealthChecker redis健康检查，通过PING命令判断是否可用，ctx结束时返回ctx的错误
+func (r *Redis) HealthChecker() health.Checker {
+	return health.CheckerFunc(func(ctx context.Context) error {
+		done := make(chan error, 1)
+		go func() {
+			done <- r.client.Ping().Err()
+		}()
+		select {
+		case err := <-done:
+			return err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	})
+}
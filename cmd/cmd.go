@@ -16,7 +16,10 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
 	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/config"
 	"os"
 )
 
@@ -38,13 +41,23 @@ var (
 			Usage:   "service zone",
 			EnvVars: []string{"CERES_ZONE"},
 		},
+		&cli.StringFlag{
+			Name:  "config-key-file",
+			Usage: "key file used to decrypt ENC(...) config values",
+		},
 	}
 )
 
 type Command struct {
-	ctx  *cli.Context
-	app  *cli.App
-	opts Options
+	ctx        *cli.Context
+	app        *cli.App
+	opts       Options
+	standalone map[*cli.Command]bool // 不需要加载配置的子命令
+}
+
+// New 创建命令交互，用于需要独立命令行的引擎
+func New(opts ...Option) *Command {
+	return newCmd(opts...)
 }
 
 // newCmd 创建命令交互
@@ -91,6 +104,14 @@ func newCmd(opts ...Option) *Command {
 	cmd.app.Action = func(context *cli.Context) error {
 		return nil
 	}
+	secret, conf := secretCommand(), configCommand()
+	cmd.standalone = map[*cli.Command]bool{secret: true}
+	// 生成配置模板时配置文件通常还不存在
+	for _, sub := range conf.Subcommands {
+		if sub.Name == "init" {
+			cmd.standalone[sub] = true
+		}
+	}
 	cmd.app.Commands = []*cli.Command{
 		{
 			Name:    "version",
@@ -102,23 +123,38 @@ func newCmd(opts ...Option) *Command {
 				return nil
 			},
 		},
+		secret,
+		conf,
 	}
 	return cmd
 }
 
+// AddCommand 添加子命令，需要在Init之前调用
+func (c *Command) AddCommand(cmds ...*cli.Command) {
+	c.app.Commands = append(c.app.Commands, cmds...)
+}
+
 // Init 初始化命令行
 func (c *Command) Init(opts ...Option) error {
+	return c.InitContext(context.Background(), opts...)
+}
+
+// InitContext 使用上下文初始化命令行，插件可以通过cli.Context获取该上下文
+func (c *Command) InitContext(ctx context.Context, opts ...Option) error {
 	for _, o := range opts {
 		o(&c.opts)
 	}
 	c.app.Flags = append(c.app.Flags, c.opts.Flags...)
 	// 运行
-	c.app.RunAndExitOnError()
+	if err := c.app.RunContext(ctx, os.Args); err != nil {
+		_, _ = fmt.Fprintln(c.app.ErrWriter, err)
+		cli.OsExiter(1)
+	}
 	return nil
 }
 
 // Before 应用运行前
-func (c Command) Before(ctx *cli.Context) (err error) {
+func (c *Command) Before(ctx *cli.Context) (err error) {
 	showVersion := ctx.Bool("version")
 	if showVersion {
 		ShowVersion()
@@ -128,17 +164,54 @@ func (c Command) Before(ctx *cli.Context) (err error) {
 	appRegion = ctx.String("region")
 	// 获取区域
 	appZone = ctx.String("zone")
+	// 指定的密钥文件优先于配置管理器原有的密钥环，需要在加载配置源之前设置
+	if keyFile := ctx.String("config-key-file"); keyFile != "" {
+		conf := config.FromContext(ctx.Context)
+		conf.SetKeyring(config.MultiKeyring(config.FileKeyring(keyFile), conf.Keyring()))
+	}
 	// 设置context
 	c.ctx = ctx
-	// 初始化插件
-	DefaultPluginManager.Range(func(n string, p Plugin) bool {
-		err = p.Init(ctx)
-		if err != nil {
+	// 不需要配置的子命令跳过插件初始化，例如生成密钥时配置文件可能还不存在
+	if c.isStandalone(ctx.Args().Slice()) {
+		return
+	}
+	// 按顺序初始化插件
+	if err = c.Plugins().Init(ctx); err != nil {
+		return
+	}
+	// 所有配置源加载完毕后通知插件
+	err = c.Plugins().Config()
+	return
+}
+
+// Plugins 获取命令行使用的插件管理器，默认为DefaultPluginManager
+func (c *Command) Plugins() *PluginManager {
+	if c.opts.Plugins != nil {
+		return c.opts.Plugins
+	}
+	return DefaultPluginManager
+}
+
+// isStandalone 判断运行的子命令是否不需要加载配置
+func (c *Command) isStandalone(args []string) bool {
+	cmds := c.app.Commands
+	for _, arg := range args {
+		var found *cli.Command
+		for _, cmd := range cmds {
+			if cmd.HasName(arg) {
+				found = cmd
+				break
+			}
+		}
+		if found == nil {
 			return false
 		}
-		return true
-	})
-	return
+		if c.standalone[found] {
+			return true
+		}
+		cmds = found.Subcommands
+	}
+	return false
 }
 
 // App 获取应用信息
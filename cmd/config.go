@@ -0,0 +1,190 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/config"
+	"sort"
+	"strings"
+)
+
+// configCommand 查看和校验配置的命令，输出的配置已脱敏
+func configCommand() *cli.Command {
+	formatFlag := &cli.StringFlag{
+		Name:    "format",
+		Aliases: []string{"f"},
+		Usage:   "output format (json, yaml, toml)",
+		Value:   "json",
+	}
+	return &cli.Command{
+		Name:  "config",
+		Usage: "inspect and validate the effective config",
+		Subcommands: []*cli.Command{
+			{
+				Name:   "dump",
+				Usage:  "print the merged effective config",
+				Flags:  []cli.Flag{formatFlag},
+				Action: configDump,
+			},
+			{
+				Name:      "get",
+				Usage:     "print a single config value",
+				ArgsUsage: "<path>",
+				Flags:     []cli.Flag{formatFlag},
+				Action:    configGet,
+			},
+			{
+				Name:  "init",
+				Usage: "print a commented config template of the linked components",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "format",
+						Aliases: []string{"f"},
+						Usage:   "template format (toml, yaml)",
+						Value:   "toml",
+					},
+				},
+				Action: configInit,
+			},
+			{
+				Name:   "validate",
+				Usage:  "scan every registered component config without starting servers",
+				Action: configValidate,
+			},
+		},
+	}
+}
+
+// configDump 输出合并后的配置
+func configDump(ctx *cli.Context) error {
+	b, err := marshalConfig(config.FromContext(ctx.Context).Masked(), ctx.String("format"))
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(ctx.App.Writer, string(b))
+	cli.OsExiter(0)
+	return nil
+}
+
+// configGet 输出指定路径的配置，对象和列表按指定格式输出
+func configGet(ctx *cli.Context) error {
+	path := ctx.Args().First()
+	if path == "" {
+		return fmt.Errorf("config get: path is required")
+	}
+	b, err := json.Marshal(config.FromContext(ctx.Context).Masked())
+	if err != nil {
+		return err
+	}
+	value := config.NewJSONValues(b).Get(path)
+	if value.IsEmpty() {
+		return fmt.Errorf("config get: %s not found", path)
+	}
+	var data interface{}
+	if err := value.Scan(&data); err != nil {
+		return err
+	}
+	switch data.(type) {
+	case map[string]interface{}, []interface{}:
+		if b, err = marshalConfig(data, ctx.String("format")); err != nil {
+			return err
+		}
+		_, _ = fmt.Fprint(ctx.App.Writer, string(b))
+	default:
+		_, _ = fmt.Fprintln(ctx.App.Writer, string(value.Bytes()))
+	}
+	cli.OsExiter(0)
+	return nil
+}
+
+// configInit 输出已注册组件的默认配置模板
+func configInit(ctx *cli.Context) error {
+	b, err := config.Template(ctx.String("format"))
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(ctx.App.Writer, string(b))
+	cli.OsExiter(0)
+	return nil
+}
+
+// configValidate 按已注册的组件解析各自的配置，只解析配置中存在的部分
+func configValidate(ctx *cli.Context) error {
+	conf := config.FromContext(ctx.Context)
+	failed := 0
+	for _, component := range config.Components() {
+		section := conf.Get(component.Prefix)
+		if section.IsEmpty() {
+			continue
+		}
+		names := []string{""}
+		if component.Named {
+			names = names[:0]
+			items := make(map[string]interface{})
+			_ = section.Scan(&items)
+			for name, item := range items {
+				if _, ok := item.(map[string]interface{}); ok {
+					names = append(names, name)
+				}
+			}
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			key := strings.TrimSuffix(component.Prefix+"."+name, ".")
+			if err := scanComponent(ctx, component, name); err != nil {
+				failed++
+				_, _ = fmt.Fprintf(ctx.App.Writer, "FAIL %s: %v\n", key, err)
+				continue
+			}
+			_, _ = fmt.Fprintf(ctx.App.Writer, "ok   %s\n", key)
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("config validate: %d component config(s) invalid", failed)
+	}
+	cli.OsExiter(0)
+	return nil
+}
+
+// scanComponent 解析组件配置，组件配置无效时会panic，转换为错误返回
+func scanComponent(ctx *cli.Context, component config.Component, name string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	component.Scan(ctx.Context, name)
+	return nil
+}
+
+// marshalConfig 按指定格式编码配置
+func marshalConfig(data interface{}, format string) ([]byte, error) {
+	fn, ok := config.Marshals[format]
+	if !ok {
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+	b, err := fn(data)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasSuffix(string(b), "\n") {
+		b = append(b, '\n')
+	}
+	return b, nil
+}
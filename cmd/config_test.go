@@ -0,0 +1,113 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/config"
+	"strings"
+	"testing"
+)
+
+func TestConfigCommand(t *testing.T) {
+	exiter := cli.OsExiter
+	defer func() {
+		cli.OsExiter = exiter
+	}()
+	cli.OsExiter = func(int) {}
+
+	config.RegisterComponent(config.Component{
+		Prefix: "test.server",
+		Named:  true,
+		Default: func() interface{} {
+			return map[string]interface{}{"port": 8080}
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			port := config.FromContext(ctx).Get("test.server." + name + ".port").Int(0)
+			if port <= 0 {
+				panic(errors.New("invalid port"))
+			}
+			return port
+		},
+	})
+	err := config.LoadContent([]byte(`{"test":{"server":{"main":{"port":8080,"password":"s3cret"},"admin":{"port":-1}}}}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) (string, error) {
+		app := New().App()
+		out := bytes.NewBuffer(nil)
+		app.Writer = out
+		err := app.Run(append([]string{"app", "config"}, args...))
+		return out.String(), err
+	}
+	out, err := run("dump", "--format", "yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "port: 8080") || strings.Contains(out, "s3cret") {
+		t.Fatalf("dump output = %s", out)
+	}
+	if out, _ = run("get", "test.server.main.port"); out != "8080\n" {
+		t.Fatalf("get output = %q", out)
+	}
+	if out, _ = run("get", "test.server.main.password"); strings.Contains(out, "s3cret") {
+		t.Fatalf("get output = %q", out)
+	}
+	if _, err = run("get", "test.server.none"); err == nil {
+		t.Fatal("expect not found error")
+	}
+	if out, _ = run("init"); !strings.Contains(out, "# [test.server.default]\n# port = 8080\n") {
+		t.Fatalf("init output = %s", out)
+	}
+	out, err = run("validate")
+	if err == nil {
+		t.Fatal("expect validate error")
+	}
+	if !strings.Contains(out, "FAIL test.server.admin: invalid port") || !strings.Contains(out, "ok   test.server.main") {
+		t.Fatalf("validate output = %s", out)
+	}
+}
+
+func TestConfigCommandContext(t *testing.T) {
+	exiter := cli.OsExiter
+	defer func() {
+		cli.OsExiter = exiter
+	}()
+	cli.OsExiter = func(int) {}
+
+	// 引擎使用独立的配置时输出该配置
+	conf := config.NewConfig()
+	if err := conf.Load([]byte(`{"scoped":{"name":"engine"}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	ctx := config.NewContext(context.Background(), conf)
+	for _, args := range [][]string{{"dump"}, {"get", "scoped.name"}} {
+		app := New().App()
+		out := bytes.NewBuffer(nil)
+		app.Writer = out
+		if err := app.RunContext(ctx, append([]string{"app", "config"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(out.String(), "engine") {
+			t.Fatalf("config %v output = %s", args, out)
+		}
+	}
+}
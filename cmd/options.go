@@ -23,6 +23,7 @@ type Options struct {
 	Description string
 	Version     string
 	Flags       []cli.Flag
+	Plugins     *PluginManager
 }
 
 type Option func(o *Options)
@@ -61,3 +62,10 @@ func WithFlags(flags []cli.Flag) Option {
 		o.Flags = append(o.Flags, flags...)
 	}
 }
+
+// WithPlugins 使用独立的插件管理器，默认为DefaultPluginManager
+func WithPlugins(m *PluginManager) Option {
+	return func(o *Options) {
+		o.Plugins = m
+	}
+}
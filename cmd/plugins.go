@@ -19,20 +19,29 @@ import (
 	"fmt"
 	"github.com/go-ceres/cli/v2"
 	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/logger"
+	"sort"
+	"strings"
 	"sync"
 )
 
 var (
-	DefaultPluginManager = &PluginManager{
-		smu:     sync.RWMutex{},
-		plugins: make(map[string]Plugin),
-	}
+	DefaultPluginManager = NewPluginManager()
 )
 
 // PluginManager 插件管理器定义
 type PluginManager struct {
 	smu     sync.RWMutex
 	plugins map[string]Plugin
+	order   []string // 注册顺序
+	inited  []Plugin // 已初始化的插件，按初始化顺序
+}
+
+// NewPluginManager 创建插件管理器，用于需要独立插件的命令行
+func NewPluginManager() *PluginManager {
+	return &PluginManager{
+		plugins: make(map[string]Plugin),
+	}
 }
 
 // Plugin 插件接口定义
@@ -49,6 +58,16 @@ type Plugin interface {
 	Destroy()
 }
 
+// PriorityPlugin 定义了优先级的插件，数值越小越先初始化，默认为0
+type PriorityPlugin interface {
+	Priority() int
+}
+
+// DependentPlugin 定义了依赖的插件，依赖的插件会先于该插件初始化
+type DependentPlugin interface {
+	Depends() []string
+}
+
 // Register 注册插件
 func (m *PluginManager) Register(p Plugin) error {
 	m.smu.Lock()
@@ -64,6 +83,7 @@ func (m *PluginManager) Register(p Plugin) error {
 	}
 	// 注册插件
 	m.plugins[name] = p
+	m.order = append(m.order, name)
 	return nil
 }
 
@@ -78,16 +98,149 @@ func (m *PluginManager) IsRegistered(p Plugin) bool {
 	return true
 }
 
-// Range 循环插件
+// Sorted 按依赖及优先级排序插件
+// 依赖的插件排在前面，没有依赖关系的插件按优先级、注册顺序排列
+func (m *PluginManager) Sorted() ([]Plugin, error) {
+	m.smu.RLock()
+	defer m.smu.RUnlock()
+	// 按优先级及注册顺序排列候选插件
+	names := make([]string, len(m.order))
+	copy(names, m.order)
+	sort.SliceStable(names, func(i, j int) bool {
+		return pluginPriority(m.plugins[names[i]]) < pluginPriority(m.plugins[names[j]])
+	})
+	// 入度及被依赖关系
+	inDegree := make(map[string]int, len(names))
+	dependents := make(map[string][]string, len(names))
+	for _, name := range names {
+		for _, dep := range pluginDepends(m.plugins[name]) {
+			if _, ok := m.plugins[dep]; !ok {
+				return nil, errors.New(500, fmt.Sprintf("plugin %s depends on unregistered plugin %s", name, dep)).WithMod("cmd")
+			}
+			inDegree[name]++
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+	res := make([]Plugin, 0, len(names))
+	done := make(map[string]bool, len(names))
+	for len(res) < len(names) {
+		progressed := false
+		for _, name := range names {
+			if done[name] || inDegree[name] > 0 {
+				continue
+			}
+			done[name] = true
+			progressed = true
+			res = append(res, m.plugins[name])
+			for _, dependent := range dependents[name] {
+				inDegree[dependent]--
+			}
+			// 每次只取一个，保证优先级高的插件尽早初始化
+			break
+		}
+		if !progressed {
+			cycle := make([]string, 0)
+			for _, name := range names {
+				if !done[name] {
+					cycle = append(cycle, name)
+				}
+			}
+			return nil, errors.New(500, fmt.Sprintf("plugin dependency cycle: %s", strings.Join(cycle, ", "))).WithMod("cmd")
+		}
+	}
+	return res, nil
+}
+
+// Range 按初始化顺序循环插件，依赖关系有误时按优先级及注册顺序循环
 func (m *PluginManager) Range(fn func(n string, p Plugin) bool) {
-	for s, plugin := range m.plugins {
+	plugins, err := m.Sorted()
+	if err != nil {
+		plugins = m.byPriority()
+	}
+	for _, plugin := range plugins {
+		if !fn(plugin.Name(), plugin) {
+			break
+		}
+	}
+}
+
+// Init 按顺序初始化所有插件，出错时返回出错插件的名称
+func (m *PluginManager) Init(ctx *cli.Context) error {
+	plugins, err := m.Sorted()
+	if err != nil {
+		return err
+	}
+	for _, p := range plugins {
+		if err := p.Init(ctx); err != nil {
+			return errors.New(500, fmt.Sprintf("plugin %s init failed: %s", p.Name(), err.Error())).WithMod("cmd")
+		}
 		m.smu.Lock()
-		b := fn(s, plugin)
+		m.inited = append(m.inited, p)
 		m.smu.Unlock()
-		if !b {
-			break
+	}
+	return nil
+}
+
+// Config 所有插件初始化完成(配置源加载完毕)后，按顺序调用插件的Config方法
+func (m *PluginManager) Config() error {
+	m.smu.RLock()
+	plugins := make([]Plugin, len(m.inited))
+	copy(plugins, m.inited)
+	m.smu.RUnlock()
+	for _, p := range plugins {
+		if err := p.Config(); err != nil {
+			return errors.New(500, fmt.Sprintf("plugin %s config failed: %s", p.Name(), err.Error())).WithMod("cmd")
 		}
 	}
+	return nil
+}
+
+// Destroy 按初始化的相反顺序销毁已初始化的插件
+func (m *PluginManager) Destroy() {
+	m.smu.Lock()
+	plugins := m.inited
+	m.inited = nil
+	m.smu.Unlock()
+	for i := len(plugins) - 1; i >= 0; i-- {
+		func(p Plugin) {
+			defer func() {
+				if rec := recover(); rec != nil {
+					logger.FrameLogger.Errord("plugin destroy panic", logger.FieldMod("cmd"), logger.FieldString("plugin", p.Name()), logger.FieldAny("panic", rec))
+				}
+			}()
+			p.Destroy()
+		}(plugins[i])
+	}
+}
+
+// byPriority 按优先级及注册顺序排列插件
+func (m *PluginManager) byPriority() []Plugin {
+	m.smu.RLock()
+	defer m.smu.RUnlock()
+	res := make([]Plugin, 0, len(m.order))
+	for _, name := range m.order {
+		res = append(res, m.plugins[name])
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return pluginPriority(res[i]) < pluginPriority(res[j])
+	})
+	return res
+}
+
+// pluginPriority 获取插件优先级
+func pluginPriority(p Plugin) int {
+	if pp, ok := p.(PriorityPlugin); ok {
+		return pp.Priority()
+	}
+	return 0
+}
+
+// pluginDepends 获取插件依赖
+func pluginDepends(p Plugin) []string {
+	if dp, ok := p.(DependentPlugin); ok {
+		return dp.Depends()
+	}
+	return nil
 }
 
 // RegisterPlugin 注册插件
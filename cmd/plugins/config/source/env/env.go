@@ -0,0 +1,78 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/cmd"
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/logger"
+	"github.com/go-ceres/go-ceres/source/env"
+)
+
+type envPlugin struct{}
+
+// Priority 环境变量配置源在文件和etcd配置源之后加载，作为覆盖层
+func (f *envPlugin) Priority() int {
+	return -90
+}
+
+// Name 插件名称
+func (f *envPlugin) Name() string {
+	return "config.source.env"
+}
+
+// Flags 需要注册的Flag命令
+func (f *envPlugin) Flags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:    "env-prefix",
+			Usage:   "environment variable prefix of configuration",
+			Value:   cli.NewStringSlice(env.DefaultPrefix),
+			EnvVars: []string{"CERES_CONFIG_ENV_PREFIX"},
+		}, &cli.StringFlag{
+			Name:    "env-separator",
+			Usage:   "environment variable path separator",
+			Value:   "_",
+			EnvVars: []string{"CERES_CONFIG_ENV_SEPARATOR"},
+		},
+	}
+}
+
+// Init 初始化方法
+func (f *envPlugin) Init(ctx *cli.Context) error {
+	source := env.NewSource(
+		env.Prefix(ctx.StringSlice("env-prefix")...),
+		env.Separator(ctx.String("env-separator")),
+	)
+	return config.FromContext(ctx.Context).LoadSource(source)
+}
+
+// Config 当配置组件初始化完成后
+func (f *envPlugin) Config() error {
+	return nil
+}
+
+// Destroy 当服务销毁时调用
+func (f *envPlugin) Destroy() {}
+
+func init() {
+	p := &envPlugin{}
+	err := cmd.RegisterPlugin(p)
+	if err != nil {
+		logger.FrameLogger.Panicd("register plugin", logger.FieldErr(err))
+	}
+}
@@ -24,6 +24,14 @@ import (
 )
 
 type etcdPlugin struct {
+	source config.Source
+	conf   config.Config
+	watch  bool
+}
+
+// Priority 配置源插件需要先于其他插件初始化
+func (f *etcdPlugin) Priority() int {
+	return -100
 }
 
 // Name 插件名称
@@ -31,24 +39,27 @@ func (f *etcdPlugin) Name() string {
 	return "config.source.etcd"
 }
 
-// Flags 需要注册的Flag命令
+// Flags 需要注册的Flag命令，flag带有etcd-前缀，环境变量带有CERES_CONFIG_ETCD_前缀，避免与文件配置源插件冲突
 func (f *etcdPlugin) Flags() []cli.Flag {
 	return []cli.Flag{
 		&cli.StringSliceFlag{
 			Name:    "endpoints",
 			Aliases: []string{"e"},
 			Usage:   "configuration file path",
-			EnvVars: []string{"CERES_CONFIG_ENDPOINTS"},
+			EnvVars: []string{"CERES_CONFIG_ETCD_ENDPOINTS", "CERES_CONFIG_ENDPOINTS"},
 		}, &cli.StringFlag{
-			Name:    "decode",
-			Aliases: []string{"d"},
-			Usage:   "profile decoder",
-			EnvVars: []string{"CERES_CONFIG_DECODE"},
+			Name:    "etcd-decode",
+			Usage:   "etcd profile decoder",
+			EnvVars: []string{"CERES_CONFIG_ETCD_DECODE"},
 		}, &cli.BoolFlag{
-			Name:    "prefix",
-			Aliases: []string{"p"},
+			Name:    "etcd-watch",
+			Usage:   "Whether to monitor etcd configuration changes",
+			EnvVars: []string{"CERES_CONFIG_ETCD_WATCH"},
+		}, &cli.StringFlag{
+			Name:    "etcd-prefix",
 			Usage:   "etcd path prefix",
-			EnvVars: []string{"CERES_CONFIG_PREFIX"},
+			Value:   etcd.DefaultPrefix,
+			EnvVars: []string{"CERES_CONFIG_ETCD_PREFIX"},
 		},
 	}
 }
@@ -56,23 +67,36 @@ func (f *etcdPlugin) Flags() []cli.Flag {
 // Init 初始化方法
 func (f *etcdPlugin) Init(ctx *cli.Context) error {
 	conf := etcd.DefaultConfig()
-	conf.Endpoints = ctx.StringSlice("endpoints")
-	conf.Encoding = ctx.String("decode")
-	err := config.Load(conf.Build())
-	if err != nil {
-		return err
+	if endpoints := ctx.StringSlice("endpoints"); len(endpoints) > 0 {
+		conf.Endpoints = endpoints
 	}
-	return nil
+	if decode := ctx.String("etcd-decode"); decode != "" {
+		conf.Encoding = decode
+	}
+	if prefix := ctx.String("etcd-prefix"); prefix != "" {
+		conf.Prefix = prefix
+	}
+	f.watch = ctx.Bool("etcd-watch")
+	// 加载到引擎的配置管理器，未指定时为全局配置
+	f.conf = config.FromContext(ctx.Context)
+	f.source = conf.Build()
+	return f.conf.LoadSource(f.source)
 }
 
 // Config 当配置组件初始化完成后
 func (f *etcdPlugin) Config() error {
-	panic("implement me")
+	// 所有配置加载完成后再监听，避免初始化过程中触发变更回调，只监听etcd配置源
+	if f.watch {
+		f.conf.WatchSource(f.source)
+	}
+	return nil
 }
 
 // Destroy 当服务销毁时调用
 func (f *etcdPlugin) Destroy() {
-	panic("implement me")
+	if f.watch {
+		f.conf.UnWatchSource(f.source)
+	}
 }
 
 func init() {
@@ -0,0 +1,64 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package etcd
+
+import (
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/cmd"
+	_ "github.com/go-ceres/go-ceres/cmd/plugins/config/source/env"
+	_ "github.com/go-ceres/go-ceres/cmd/plugins/config/source/file"
+	"testing"
+)
+
+func TestFlagsWithFilePlugin(t *testing.T) {
+	// 与其他配置源插件一起注册时flag名称不能冲突
+	names := make(map[string]string)
+	flags := append([]cli.Flag{}, cmd.DefaultFlags...)
+	cmd.RangePlugins(func(n string, p cmd.Plugin) bool {
+		flags = append(flags, p.Flags()...)
+		for _, flag := range p.Flags() {
+			for _, name := range flag.Names() {
+				if other, ok := names[name]; ok && other != n {
+					t.Errorf("flag %s of %s conflicts with %s", name, n, other)
+				}
+				names[name] = n
+			}
+		}
+		return true
+	})
+	app := cli.NewApp()
+	app.Flags = flags
+	var watch, fileWatch bool
+	app.Action = func(ctx *cli.Context) error {
+		watch, fileWatch = ctx.Bool("etcd-watch"), ctx.Bool("watch")
+		return nil
+	}
+	if err := app.Run([]string{"app", "--etcd-watch", "--etcd-prefix", "/app/"}); err != nil {
+		t.Fatal(err)
+	}
+	if !watch || fileWatch {
+		t.Fatalf("etcd-watch = %v, watch = %v", watch, fileWatch)
+	}
+	// 环境变量同样互不影响
+	t.Setenv("CERES_CONFIG_ETCD_WATCH", "true")
+	watch, fileWatch = false, false
+	if err := app.Run([]string{"app"}); err != nil {
+		t.Fatal(err)
+	}
+	if !watch || fileWatch {
+		t.Fatalf("env etcd-watch = %v, watch = %v", watch, fileWatch)
+	}
+}
@@ -25,7 +25,15 @@ import (
 
 // filePlugin
 type filePlugin struct {
-	source config.Source
+	source  config.Source
+	profile config.Source // 环境配置源
+	conf    config.Config
+	watch   bool
+}
+
+// Priority 配置源插件需要先于其他插件初始化
+func (f *filePlugin) Priority() int {
+	return -100
 }
 
 // Name 插件名称
@@ -39,13 +47,17 @@ func (f *filePlugin) Flags() []cli.Flag {
 		&cli.StringFlag{
 			Name:    "file",
 			Aliases: []string{"f"},
-			Usage:   "configuration file path",
+			Usage:   "configuration file or directory path",
 			EnvVars: []string{"CERES_CONFIG_FILE"},
 		}, &cli.StringFlag{
 			Name:    "decode",
 			Aliases: []string{"d"},
 			Usage:   "profile decoder",
 			EnvVars: []string{"CERES_CONFIG_DECODE"},
+		}, &cli.StringFlag{
+			Name:    "profile",
+			Usage:   "profile whose config.<profile>.toml overrides the configuration",
+			EnvVars: []string{"CERES_PROFILE"},
 		}, &cli.BoolFlag{
 			Name:    "watch",
 			Aliases: []string{"w"},
@@ -66,22 +78,44 @@ func (f *filePlugin) Init(ctx *cli.Context) error {
 	if decode != "" {
 		opts = append(opts, file.Unmarshal(decode))
 	}
+	f.watch = ctx.Bool("watch")
 	f.source = file.NewSource(path, opts...)
-	err := config.Load(f.source)
+	// 加载到引擎的配置管理器，未指定时为全局配置
+	f.conf = config.FromContext(ctx.Context)
+	err := f.conf.LoadSource(f.source)
 	if err != nil {
 		return err
 	}
+	// 环境配置叠加在基础配置之上
+	if profile := ctx.String("profile"); profile != "" {
+		f.profile = file.NewSource(path, append(opts, file.Profile(profile))...)
+		if err := f.conf.LoadSource(f.profile); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
 // Config 当配置组件初始化完成后
 func (f *filePlugin) Config() error {
-	panic("implement me")
+	// 所有配置加载完成后再监听，避免初始化过程中触发变更回调，只监听文件配置源
+	if f.watch {
+		f.conf.WatchSource(f.source)
+		if f.profile != nil {
+			f.conf.WatchSource(f.profile)
+		}
+	}
+	return nil
 }
 
 // Destroy 当服务销毁时调用
 func (f *filePlugin) Destroy() {
-	panic("implement me")
+	if f.watch {
+		f.conf.UnWatchSource(f.source)
+		if f.profile != nil {
+			f.conf.UnWatchSource(f.profile)
+		}
+	}
 }
 
 func init() {
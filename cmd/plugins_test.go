@@ -0,0 +1,101 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-ceres/cli/v2"
+)
+
+type testPlugin struct {
+	name     string
+	priority int
+	depends  []string
+	initErr  error
+	trace    *[]string
+}
+
+func (p *testPlugin) Name() string      { return p.name }
+func (p *testPlugin) Flags() []cli.Flag { return nil }
+func (p *testPlugin) Priority() int     { return p.priority }
+func (p *testPlugin) Depends() []string { return p.depends }
+func (p *testPlugin) Config() error     { return nil }
+func (p *testPlugin) Destroy()          { *p.trace = append(*p.trace, "destroy:"+p.name) }
+func (p *testPlugin) Init(ctx *cli.Context) error {
+	*p.trace = append(*p.trace, "init:"+p.name)
+	return p.initErr
+}
+
+func newTestManager(plugins ...*testPlugin) *PluginManager {
+	m := &PluginManager{smu: sync.RWMutex{}, plugins: make(map[string]Plugin)}
+	for _, p := range plugins {
+		_ = m.Register(p)
+	}
+	return m
+}
+
+func TestPluginManager_Sorted(t *testing.T) {
+	var trace []string
+	m := newTestManager(
+		&testPlugin{name: "registry", depends: []string{"etcd"}, trace: &trace},
+		&testPlugin{name: "etcd", priority: 10, trace: &trace},
+		&testPlugin{name: "config", priority: -100, trace: &trace},
+		&testPlugin{name: "logger", trace: &trace},
+	)
+	plugins, err := m.Sorted()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, p := range plugins {
+		names = append(names, p.Name())
+	}
+	if got, want := strings.Join(names, ","), "config,logger,etcd,registry"; got != want {
+		t.Fatalf("sorted = %s, want %s", got, want)
+	}
+}
+
+func TestPluginManager_InitAndDestroy(t *testing.T) {
+	var trace []string
+	m := newTestManager(
+		&testPlugin{name: "a", trace: &trace},
+		&testPlugin{name: "b", trace: &trace, initErr: errors.New("boom")},
+		&testPlugin{name: "c", trace: &trace},
+	)
+	err := m.Init(nil)
+	if err == nil || !strings.Contains(err.Error(), "plugin b init failed") {
+		t.Fatalf("init err = %v, want plugin b init failed", err)
+	}
+	m.Destroy()
+	if got, want := strings.Join(trace, ","), "init:a,init:b,destroy:a"; got != want {
+		t.Fatalf("trace = %s, want %s", got, want)
+	}
+}
+
+func TestPluginManager_Cycle(t *testing.T) {
+	var trace []string
+	m := newTestManager(
+		&testPlugin{name: "a", depends: []string{"b"}, trace: &trace},
+		&testPlugin{name: "b", depends: []string{"a"}, trace: &trace},
+	)
+	if _, err := m.Sorted(); err == nil {
+		t.Fatal("sorted should fail on dependency cycle")
+	}
+}
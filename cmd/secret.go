@@ -0,0 +1,91 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/config"
+	"io/ioutil"
+	"strings"
+)
+
+// secretCommand 加解密配置值的命令，未指定值时从标准输入读取，避免明文出现在命令历史中
+func secretCommand() *cli.Command {
+	return &cli.Command{
+		Name:  "secret",
+		Usage: "encrypt or decrypt config values with the config keyring",
+		Subcommands: []*cli.Command{
+			{
+				Name:      "encrypt",
+				Usage:     "encrypt a value as ENC(...)",
+				ArgsUsage: "[value]",
+				Action:    secretAction(config.EncryptWith),
+			},
+			{
+				Name:      "decrypt",
+				Usage:     "decrypt an ENC(...) value",
+				ArgsUsage: "[value]",
+				Action:    secretAction(config.DecryptWith),
+			},
+			{
+				Name:  "keygen",
+				Usage: "generate a new base64 encoded AES-256 key",
+				Action: func(ctx *cli.Context) error {
+					key, err := config.GenerateKey()
+					if err != nil {
+						return err
+					}
+					_, _ = fmt.Fprintln(ctx.App.Writer, key)
+					cli.OsExiter(0)
+					return nil
+				},
+			},
+		},
+	}
+}
+
+// secretAction 使用配置管理器的密钥环执行加解密并输出结果后退出
+func secretAction(fn func(config.Keyring, string) (string, error)) cli.ActionFunc {
+	return func(ctx *cli.Context) error {
+		value := ctx.Args().First()
+		if !ctx.Args().Present() {
+			b, err := ioutil.ReadAll(rootApp(ctx).Reader)
+			if err != nil {
+				return err
+			}
+			value = strings.TrimRight(string(b), "\r\n")
+		}
+		res, err := fn(config.FromContext(ctx.Context).Keyring(), value)
+		if err != nil {
+			return err
+		}
+		_, _ = fmt.Fprintln(ctx.App.Writer, res)
+		cli.OsExiter(0)
+		return nil
+	}
+}
+
+// rootApp 获取根App，子命令的App不会继承Reader
+func rootApp(ctx *cli.Context) *cli.App {
+	app := ctx.App
+	for _, c := range ctx.Lineage() {
+		if c.App != nil {
+			app = c.App
+		}
+	}
+	return app
+}
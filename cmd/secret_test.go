@@ -0,0 +1,128 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/config"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecretCommand(t *testing.T) {
+	key, _ := config.GenerateKey()
+	t.Setenv(config.KeyEnv, key)
+	exiter := cli.OsExiter
+	defer func() {
+		cli.OsExiter = exiter
+	}()
+	cli.OsExiter = func(int) {}
+
+	run := func(stdin string, args ...string) string {
+		app := New().App()
+		out := bytes.NewBuffer(nil)
+		app.Reader = strings.NewReader(stdin)
+		app.Writer = out
+		if err := app.Run(append([]string{"app", "secret"}, args...)); err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(out.String())
+	}
+	enc := run("s3cret\n", "encrypt")
+	if !config.IsEncrypted(enc) {
+		t.Fatalf("encrypt output = %s", enc)
+	}
+	if plain := run("", "decrypt", enc); plain != "s3cret" {
+		t.Fatalf("decrypt output = %s", plain)
+	}
+}
+
+func TestStandaloneCommandsWithoutConfig(t *testing.T) {
+	t.Setenv(config.KeyEnv, "")
+	exiter, manager := cli.OsExiter, DefaultPluginManager
+	defer func() {
+		cli.OsExiter, DefaultPluginManager = exiter, manager
+	}()
+	cli.OsExiter = func(int) {}
+	// 模拟配置文件不存在时加载配置的插件初始化失败
+	var trace []string
+	DefaultPluginManager = &PluginManager{plugins: make(map[string]Plugin)}
+	if err := DefaultPluginManager.Register(&testPlugin{name: "config.source.file", initErr: errors.New("open config.toml: no such file"), trace: &trace}); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(stdin string, args ...string) (string, error) {
+		app := New().App()
+		out := bytes.NewBuffer(nil)
+		app.Reader = strings.NewReader(stdin)
+		app.Writer = out
+		err := app.Run(append([]string{"app"}, args...))
+		return strings.TrimSpace(out.String()), err
+	}
+	key, err := run("", "secret", "keygen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(config.KeyEnv, key)
+	enc, err := run("s3cret", "secret", "encrypt")
+	if err != nil || !config.IsEncrypted(enc) {
+		t.Fatalf("encrypt output = %s, %v", enc, err)
+	}
+	if out, err := run("", "config", "init"); err != nil || !strings.Contains(out, "config template") {
+		t.Fatalf("config init output = %s, %v", out, err)
+	}
+	if len(trace) != 0 {
+		t.Fatalf("plugins should not init for standalone commands: %v", trace)
+	}
+	if _, err := run("", "config", "dump"); err == nil {
+		t.Fatal("expect plugin init error for config dump")
+	}
+}
+
+func TestSecretCommandKeyFile(t *testing.T) {
+	t.Setenv(config.KeyEnv, "")
+	exiter := cli.OsExiter
+	defer func() {
+		cli.OsExiter = exiter
+	}()
+	cli.OsExiter = func(int) {}
+	key, _ := config.GenerateKey()
+	path := filepath.Join(t.TempDir(), "key")
+	_ = ioutil.WriteFile(path, []byte(key), 0600)
+
+	// 密钥文件只作用于上下文中的配置管理器
+	conf := config.NewConfig()
+	app := New().App()
+	out := bytes.NewBuffer(nil)
+	app.Reader = strings.NewReader("s3cret")
+	app.Writer = out
+	ctx := config.NewContext(context.Background(), conf)
+	if err := app.RunContext(ctx, []string{"app", "--config-key-file", path, "secret", "encrypt"}); err != nil {
+		t.Fatal(err)
+	}
+	enc := strings.TrimSpace(out.String())
+	if plain, err := config.DecryptWith(conf.Keyring(), enc); err != nil || plain != "s3cret" {
+		t.Fatalf("decrypt = %s, %v", plain, err)
+	}
+	if _, err := config.Decrypt(enc); err == nil {
+		t.Fatal("key file should not change the default keyring")
+	}
+}
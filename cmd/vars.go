@@ -124,6 +124,46 @@ func GetHostname() string {
 	return hostName
 }
 
+// GetCommit 获取git提交信息
+func GetCommit() string {
+	return commit
+}
+
+// GetBranch 获取git分支信息
+func GetBranch() string {
+	return branch
+}
+
+// GetBuildStatus 获取构建状态
+func GetBuildStatus() string {
+	return buildStatus
+}
+
+// GetGoVersion 获取运行时golang版本
+func GetGoVersion() string {
+	return goVersion
+}
+
+// BuildInfo 获取构建信息
+func BuildInfo() map[string]string {
+	return map[string]string{
+		"name":         appName,
+		"version":      version,
+		"branch":       branch,
+		"commit":       commit,
+		"ceresVersion": ceresVersion,
+		"goVersion":    goVersion,
+		"buildUser":    buildUser,
+		"buildHost":    buildHost,
+		"buildTime":    buildTime,
+		"buildStatus":  buildStatus,
+		"hostname":     hostName,
+		"startTime":    startTime,
+		"region":       appRegion,
+		"zone":         appZone,
+	}
+}
+
 // ShowVersion 输出版本信息
 func ShowVersion() {
 	fmt.Printf("%-9s]> %-30s => %s\n", "go-ceres", color.RedString("name"), color.BlueString(appName))
@@ -0,0 +1,61 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/logger"
+)
+
+// CommandFunc 自定义子命令的执行方法，在配置、日志、插件、启动项以及MustSetup传入的组件初始化完成后运行，
+// 返回值作为进程的退出码
+type CommandFunc func(ctx *cli.Context, eng *Engine) int
+
+// subcommand 命令行选中的自定义子命令
+type subcommand struct {
+	ctx *cli.Context
+	fn  CommandFunc
+}
+
+// WithCommand 注册自定义子命令
+func WithCommand(c *cli.Command, fn CommandFunc) Option {
+	return func(eng *Engine) {
+		eng.SetCommand(c, fn)
+	}
+}
+
+// SetCommand 注册自定义子命令，例如migrate、seed等运维命令，需要在MustSetup之前调用，
+// 命令的Action会被替换为fn，子命令不会启动服务，执行完成后停止引擎并以fn的返回值退出
+func (eng *Engine) SetCommand(c *cli.Command, fn CommandFunc) *Engine {
+	c.Action = func(ctx *cli.Context) error {
+		eng.subcommand = &subcommand{ctx: ctx, fn: fn}
+		return nil
+	}
+	eng.commands = append(eng.commands, c)
+	return eng
+}
+
+// runCommand 运行命令行选中的自定义子命令，没有选中时返回
+func (eng *Engine) runCommand() {
+	if eng.subcommand == nil {
+		return
+	}
+	eng.logger.Infod("run command", logger.FieldMod(errors.ModApp), logger.FieldString("command", eng.subcommand.ctx.Command.Name))
+	code := eng.subcommand.fn(eng.subcommand.ctx, eng)
+	_ = eng.Stop()
+	cli.OsExiter(code)
+}
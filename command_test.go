@@ -0,0 +1,77 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"github.com/go-ceres/cli/v2"
+	"github.com/go-ceres/go-ceres/cmd"
+	"github.com/go-ceres/go-ceres/config"
+	"os"
+	"testing"
+)
+
+func TestEngineCommand(t *testing.T) {
+	exiter, args := cli.OsExiter, os.Args
+	defer func() {
+		cli.OsExiter, os.Args = exiter, args
+	}()
+	code := -1
+	cli.OsExiter = func(c int) {
+		code = c
+	}
+	os.Args = []string{"app", "migrate", "--steps", "3", "users"}
+
+	conf := config.NewConfig()
+	if err := conf.Load([]byte(`{"ceres":{"admin":{"enable":true,"port":0}},"db":{"dsn":"memory"}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	var inited, built bool
+	eng := NewEngine(WithConfig(conf), WithCmd(cmd.New()), WithCommand(&cli.Command{
+		Name:  "migrate",
+		Flags: []cli.Flag{&cli.IntFlag{Name: "steps"}},
+	}, func(ctx *cli.Context, eng *Engine) int {
+		if !inited || !built {
+			t.Error("command should run after init functions and components")
+		}
+		if dsn := eng.Config().Get("db.dsn").String(""); dsn != "memory" {
+			t.Errorf("db.dsn = %s", dsn)
+		}
+		if ctx.Int("steps") != 3 || ctx.Args().First() != "users" {
+			t.Errorf("steps = %d, args = %v", ctx.Int("steps"), ctx.Args().Slice())
+		}
+		return 3
+	}))
+	eng.SetInit(func() error {
+		inited = true
+		return nil
+	})
+	err := eng.MustSetup(func() (func(), error) {
+		built = true
+		return func() {}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code != 3 {
+		t.Fatalf("exit code = %d, want 3", code)
+	}
+	if eng.admin != nil {
+		t.Fatal("admin server should not start for commands")
+	}
+	if eng.Context().Err() == nil {
+		t.Fatal("engine should stop after command")
+	}
+}
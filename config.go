@@ -0,0 +1,46 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/logger"
+)
+
+// initConfig 配置变化时记录变化的配置路径，便于审计，配置重新加载被拒绝时记录原因
+func (eng *Engine) initConfig() error {
+	eng.conf.OnReject(func(source string, err error) {
+		eng.logger.Errord("config reload rejected, keep last good config",
+			logger.FieldMod(errors.ModConfig),
+			logger.FieldString("source", source),
+			logger.FieldErr(err),
+		)
+	})
+	eng.conf.WatchPath("", func(old, new config.Value) {
+		diff := config.Compare(old, new)
+		if diff.IsEmpty() {
+			return
+		}
+		eng.logger.Infod("config changed",
+			logger.FieldMod(errors.ModConfig),
+			logger.FieldAny("added", diff.Added),
+			logger.FieldAny("removed", diff.Removed),
+			logger.FieldAny("modified", diff.Modified),
+		)
+	})
+	return nil
+}
@@ -0,0 +1,170 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Validator 配置校验接口，绑定的配置实现该接口时每次更新前都会校验
+type Validator interface {
+	Validate() error
+}
+
+// BindOption 配置绑定选项
+type BindOption[T any] func(b *Binding[T])
+
+// BindDefault 设置生成默认配置的方法，每次解析前先生成默认配置
+func BindDefault[T any](fn func() *T) BindOption[T] {
+	return func(b *Binding[T]) {
+		b.def = fn
+	}
+}
+
+// BindValidator 添加配置校验方法
+func BindValidator[T any](fn func(*T) error) BindOption[T] {
+	return func(b *Binding[T]) {
+		b.validators = append(b.validators, fn)
+	}
+}
+
+// Binding 绑定到配置key的类型化配置，配置变化时重新解析并原子替换，解析或校验失败时保留原配置
+type Binding[T any] struct {
+	key        string
+	value      atomic.Value
+	mu         sync.Mutex
+	raw        []byte
+	err        error
+	closed     bool
+	unwatch    func() // 取消配置路径监听
+	def        func() *T
+	validators []func(*T) error
+	onUpdates  []func(old, new *T)
+}
+
+// Bind 根据key从默认配置管理器绑定配置，首次解析或校验失败时panic
+func Bind[T any](key string, opts ...BindOption[T]) *Binding[T] {
+	return BindConfig[T](DefaultConfig, key, opts...)
+}
+
+// BindConfig 根据key从指定的配置管理器绑定配置，首次解析或校验失败时panic
+func BindConfig[T any](conf Config, key string, opts ...BindOption[T]) *Binding[T] {
+	b := &Binding[T]{
+		key: key,
+		def: func() *T {
+			return new(T)
+		},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	val := conf.Get(key)
+	v, err := b.parse(val)
+	if err != nil {
+		panic(fmt.Sprintf("bind config %s: %v", key, err))
+	}
+	b.raw = val.Bytes()
+	b.value.Store(v)
+	b.unwatch = conf.WatchPath(key, func(_, val Value) {
+		b.update(val)
+	})
+	return b
+}
+
+// Load 获取当前生效的配置，返回值只读，不要修改
+func (b *Binding[T]) Load() *T {
+	return b.value.Load().(*T)
+}
+
+// Key 绑定的配置key
+func (b *Binding[T]) Key() string {
+	return b.key
+}
+
+// Err 最近一次更新失败的错误，更新成功后清空
+func (b *Binding[T]) Err() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.err
+}
+
+// OnUpdate 添加配置更新后的回调
+func (b *Binding[T]) OnUpdate(fn func(old, new *T)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.onUpdates = append(b.onUpdates, fn)
+}
+
+// Close 停止跟随配置变化更新，并取消配置路径监听
+func (b *Binding[T]) Close() {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return
+	}
+	b.closed = true
+	b.mu.Unlock()
+	b.unwatch()
+}
+
+// update 配置变化时重新解析，key下的内容没有变化时忽略
+func (b *Binding[T]) update(val Value) {
+	raw := val.Bytes()
+	b.mu.Lock()
+	if b.closed || bytes.Equal(raw, b.raw) {
+		b.mu.Unlock()
+		return
+	}
+	v, err := b.parse(val)
+	if err != nil {
+		b.err = err
+		b.mu.Unlock()
+		return
+	}
+	old := b.Load()
+	b.raw, b.err = raw, nil
+	b.value.Store(v)
+	onUpdates := make([]func(old, new *T), len(b.onUpdates))
+	copy(onUpdates, b.onUpdates)
+	b.mu.Unlock()
+	for _, fn := range onUpdates {
+		fn(old, v)
+	}
+}
+
+// parse 解析并校验配置
+func (b *Binding[T]) parse(val Value) (*T, error) {
+	v := b.def()
+	if !val.IsEmpty() {
+		if err := val.Scan(v); err != nil {
+			return nil, err
+		}
+	}
+	if validator, ok := interface{}(v).(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	for _, validate := range b.validators {
+		if err := validate(v); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
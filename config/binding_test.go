@@ -0,0 +1,102 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"testing"
+)
+
+type bindConfig struct {
+	PoolSize int    `json:"pool_size"`
+	Mode     string `json:"mode"`
+}
+
+func (c *bindConfig) Validate() error {
+	if c.PoolSize <= 0 {
+		return errors.New("pool_size must be positive")
+	}
+	return nil
+}
+
+func TestBinding(t *testing.T) {
+	c := NewConfig()
+	_ = c.Load([]byte(`{"redis":{"pool_size":10},"other":1}`), "json")
+	b := BindConfig[bindConfig](c, "redis", BindDefault(func() *bindConfig {
+		return &bindConfig{Mode: "simple"}
+	}), BindValidator(func(c *bindConfig) error {
+		if c.Mode != "simple" && c.Mode != "cluster" {
+			return errors.New("invalid mode")
+		}
+		return nil
+	}))
+	if v := b.Load(); v.PoolSize != 10 || v.Mode != "simple" {
+		t.Fatalf("Load() = %+v", v)
+	}
+	var updates [][2]*bindConfig
+	b.OnUpdate(func(old, new *bindConfig) {
+		updates = append(updates, [2]*bindConfig{old, new})
+	})
+
+	// 其他key变化不触发更新
+	_ = c.Set("other", 2)
+	if len(updates) != 0 {
+		t.Fatalf("updates = %d, want 0", len(updates))
+	}
+	_ = c.Set("redis.pool_size", 20)
+	if len(updates) != 1 || updates[0][0].PoolSize != 10 || updates[0][1].PoolSize != 20 {
+		t.Fatalf("updates = %+v", updates)
+	}
+	if v := b.Load(); v.PoolSize != 20 {
+		t.Fatalf("pool_size = %d, want 20", v.PoolSize)
+	}
+
+	// 校验失败时保留原配置
+	_ = c.Set("redis.pool_size", 0)
+	if v := b.Load(); v.PoolSize != 20 || b.Err() == nil {
+		t.Fatalf("pool_size = %d, err = %v", v.PoolSize, b.Err())
+	}
+	_ = c.Set("redis.mode", "unknown")
+	if len(updates) != 1 {
+		t.Fatalf("updates = %d, want 1", len(updates))
+	}
+	_ = c.Set("redis", map[string]interface{}{"pool_size": 5, "mode": "cluster"})
+	if v := b.Load(); v.PoolSize != 5 || v.Mode != "cluster" || b.Err() != nil {
+		t.Fatalf("Load() = %+v, err = %v", v, b.Err())
+	}
+
+	b.Close()
+	b.Close()
+	_ = c.Set("redis.pool_size", 6)
+	if v := b.Load(); v.PoolSize != 5 {
+		t.Fatalf("pool_size = %d, want 5", v.PoolSize)
+	}
+	// 关闭后不再保留配置监听
+	if watchers := len(c.(*config).watchers); watchers != 0 {
+		t.Fatalf("watchers = %d, want 0 after close", watchers)
+	}
+}
+
+func TestBindingInvalid(t *testing.T) {
+	c := NewConfig()
+	_ = c.Load([]byte(`{"redis":{"pool_size":0}}`), "json")
+	defer func() {
+		if recover() == nil {
+			t.Fatal("invalid config should panic")
+		}
+	}()
+	BindConfig[bindConfig](c, "redis")
+}
@@ -0,0 +1,241 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ScanFunc 按名称从上下文中的配置管理器解析组件配置，与组件的ScanConfigContext一致，配置无效时panic
+type ScanFunc func(ctx context.Context, name string) interface{}
+
+// Component 组件配置的注册信息
+type Component struct {
+	Prefix      string             // 配置前缀，例如ceres.server.grpc
+	Named       bool               // 前缀下是否按名称配置多个实例，例如ceres.server.grpc.<name>
+	Description string             // 组件说明，生成配置模板时作为注释
+	Default     func() interface{} // 默认配置，生成配置模板时使用
+	Scan        ScanFunc           // 解析配置的方法
+}
+
+// TemplateName 生成配置模板时按名称配置的组件使用的实例名称
+const TemplateName = "default"
+
+var (
+	componentsMu sync.RWMutex
+	components   = make(map[string]Component)
+)
+
+// RegisterComponent 注册组件配置，组件在init中注册，相同前缀只保留先注册的组件，
+// 例如不同版本的客户端读取同一前缀的配置时模板中只输出一份
+func RegisterComponent(c Component) {
+	componentsMu.Lock()
+	defer componentsMu.Unlock()
+	if _, ok := components[c.Prefix]; ok {
+		return
+	}
+	components[c.Prefix] = c
+}
+
+// Components 获取已注册的组件配置，按前缀排序
+func Components() []Component {
+	componentsMu.RLock()
+	defer componentsMu.RUnlock()
+	res := make([]Component, 0, len(components))
+	for _, c := range components {
+		res = append(res, c)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Prefix < res[j].Prefix
+	})
+	return res
+}
+
+// Template 根据已注册组件的默认配置生成配置模板，所有配置项均被注释，使用时取消需要的配置项的注释并修改。
+// toml按组件分别输出带完整路径的表，其他格式合并为一个文档后输出，json不支持注释。
+// 配置项前输出字段usage标签中的说明，时间间隔输出为"3s"等字符串
+func Template(format string) ([]byte, error) {
+	if format == "json" {
+		return nil, fmt.Errorf("template: format %s does not support comments", format)
+	}
+	buf := bytes.NewBuffer(nil)
+	buf.WriteString("# go-ceres config template, uncomment and edit the sections you need\n")
+	merged := make(map[string]interface{})
+	usages := make(map[string]string)
+	for _, c := range Components() {
+		if c.Default == nil {
+			continue
+		}
+		key := c.Prefix
+		if c.Named {
+			key += "." + TemplateName
+		}
+		def := c.Default()
+		data, err := normalize(def)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", c.Prefix, err)
+		}
+		data = convertValues(data, reflect.TypeOf(def), formatDuration)
+		fieldUsages(reflect.TypeOf(def), key, 8, usages)
+		section := nest(splitPath(key), prune(data))
+		title := key
+		if c.Description != "" {
+			title += ": " + c.Description
+		}
+		if format != "toml" {
+			deepMerge(merged, section)
+			buf.WriteString("# " + title + "\n")
+			continue
+		}
+		b, err := encode(section, format)
+		if err != nil {
+			return nil, fmt.Errorf("template %s: %w", c.Prefix, err)
+		}
+		buf.WriteString("\n# " + title + "\n")
+		writeComment(buf, tomlLines(strings.Split(string(b), "\n")), tomlPath(), usages)
+	}
+	if format != "toml" {
+		b, err := encode(merged, format)
+		if err != nil {
+			return nil, fmt.Errorf("template: %w", err)
+		}
+		buf.WriteString("\n")
+		writeComment(buf, strings.Split(strings.TrimRight(string(b), "\n"), "\n"), yamlPath(), usages)
+	}
+	return buf.Bytes(), nil
+}
+
+// writeComment 按行注释输出，配置项前输出该配置项的说明
+func writeComment(buf *bytes.Buffer, lines []string, path func(line string) string, usages map[string]string) {
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			buf.WriteString("#\n")
+			continue
+		}
+		if usage, ok := usages[path(line)]; ok {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " "))]
+			buf.WriteString("# " + indent + "# " + usage + "\n")
+		}
+		buf.WriteString("# " + line + "\n")
+	}
+}
+
+// tomlPath 根据表头计算toml中配置项的完整路径，非配置项返回空
+func tomlPath() func(line string) string {
+	table := ""
+	return func(line string) string {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[]")
+			return ""
+		}
+		i := strings.Index(line, " = ")
+		if i < 0 {
+			return ""
+		}
+		return table + "." + strings.Trim(line[:i], `"`)
+	}
+}
+
+// yamlPath 根据缩进计算yaml中配置项的完整路径，非配置项返回空
+func yamlPath() func(line string) string {
+	type item struct {
+		indent int
+		key    string
+	}
+	var stack []item
+	return func(line string) string {
+		trimmed := strings.TrimLeft(line, " ")
+		i := strings.Index(trimmed, ":")
+		if strings.HasPrefix(trimmed, "-") || i < 0 {
+			return ""
+		}
+		indent := len(line) - len(trimmed)
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, item{indent: indent, key: strings.Trim(trimmed[:i], `"'`)})
+		keys := make([]string, len(stack))
+		for k, s := range stack {
+			keys[k] = s.key
+		}
+		return strings.Join(keys, ".")
+	}
+}
+
+// prune 删除空值以及空的对象和列表，toml等格式不支持null
+func prune(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if item = prune(item); item != nil {
+				res[k] = item
+			}
+		}
+		if len(res) == 0 {
+			return nil
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, 0, len(val))
+		for _, item := range val {
+			if item = prune(item); item != nil {
+				res = append(res, item)
+			}
+		}
+		if len(res) == 0 {
+			return nil
+		}
+		return res
+	default:
+		return val
+	}
+}
+
+// tomlLines 去掉toml中只有子表的表头和缩进，使每个组件只有一个完整路径的表头
+func tomlLines(lines []string) []string {
+	res := make([]string, 0, len(lines))
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") && !strings.HasPrefix(line, "[[") {
+			next := ""
+			for _, l := range lines[i+1:] {
+				if l = strings.TrimSpace(l); l != "" {
+					next = l
+					break
+				}
+			}
+			if next == "" || strings.HasPrefix(next, "[") {
+				continue
+			}
+		}
+		if line == "" && (len(res) == 0 || res[len(res)-1] == "") {
+			continue
+		}
+		res = append(res, line)
+	}
+	if len(res) > 0 && res[len(res)-1] == "" {
+		res = res[:len(res)-1]
+	}
+	return res
+}
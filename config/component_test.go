@@ -0,0 +1,101 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTemplate(t *testing.T) {
+	type serverConfig struct {
+		Host    string            `json:"host" usage:"服务ip"`
+		Port    int               `json:"port" usage:"服务端口"`
+		Timeout time.Duration     `json:"timeout" usage:"超时时间"`
+		Tags    []string          `json:"tags"`
+		Extra   map[string]string `json:"extra"`
+	}
+	RegisterComponent(Component{
+		Prefix:      "test.server",
+		Named:       true,
+		Description: "test server",
+		Default: func() interface{} {
+			return &serverConfig{Host: "0.0.0.0", Port: 8080, Timeout: 3 * time.Second, Tags: []string{"a"}}
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return nil
+		},
+	})
+	// 相同前缀只保留先注册的组件
+	RegisterComponent(Component{
+		Prefix:      "test.server",
+		Description: "duplicated",
+	})
+	RegisterComponent(Component{
+		Prefix: "test.admin",
+		Default: func() interface{} {
+			return map[string]interface{}{"enable": false}
+		},
+	})
+
+	for _, format := range []string{"toml", "yaml"} {
+		b, err := Template(format)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(b), "# test.server.default: test server\n") || strings.Contains(string(b), "duplicated") {
+			t.Fatalf("%s template = %s", format, b)
+		}
+		if !strings.Contains(string(b), "# 服务端口\n") || !strings.Contains(string(b), "3s") {
+			t.Fatalf("%s template should contain field usages and duration strings:\n%s", format, b)
+		}
+		// 取消注释后应该是合法的配置
+		lines := strings.Split(string(b), "\n")
+		for i, line := range lines {
+			if strings.HasPrefix(line, "# ") && !strings.HasPrefix(line, "# test.") && !strings.HasPrefix(line, "# go-ceres") {
+				lines[i] = strings.TrimPrefix(line, "# ")
+			}
+		}
+		c := NewConfig()
+		if err := c.Load([]byte(strings.Join(lines, "\n")), format); err != nil {
+			t.Fatalf("load %s template: %v\n%s", format, err, b)
+		}
+		if port := c.Get("test.server.default.port").Int(0); port != 8080 {
+			t.Fatalf("%s: port = %d, want 8080", format, port)
+		}
+		if host := c.Get("test.server.default.host").String(""); host != "0.0.0.0" {
+			t.Fatalf("%s: host = %s", format, host)
+		}
+		if c.Get("test.admin.enable").IsEmpty() || c.Get("test.admin.enable").Bool(true) {
+			t.Fatalf("%s: test.admin.enable missing", format)
+		}
+		var conf serverConfig
+		if err := c.Get("test.server.default").Scan(&conf); err != nil {
+			t.Fatalf("%s: scan template: %v", format, err)
+		}
+		if conf.Timeout != 3*time.Second || conf.Port != 8080 {
+			t.Fatalf("%s: scanned config = %+v", format, conf)
+		}
+		if !c.Get("test.server.default.extra").IsEmpty() {
+			t.Fatalf("%s: empty values should be pruned", format)
+		}
+	}
+	if _, err := Template("json"); err == nil {
+		t.Fatal("expect error for json template")
+	}
+}
@@ -36,9 +36,49 @@ func OnChange(fn ChangeFunc) {
 	DefaultConfig.OnChange(fn)
 }
 
-// Load 从数据源获取配置信息
-func Load(sources Source) error {
-	return DefaultConfig.LoadSource(sources)
+// WatchPath 添加一个配置路径监听，只有该路径下的配置变化时才会回调，返回取消监听的方法
+func WatchPath(path string, fn PathChangeFunc) func() {
+	return DefaultConfig.WatchPath(path, fn)
+}
+
+// Load 从数据源获取配置信息，叠加到已有配置之上
+func Load(source Source, opts ...SourceOption) error {
+	return DefaultConfig.LoadSource(source, opts...)
+}
+
+// SourceOf 获取提供该配置的配置源名称
+func SourceOf(path string) string {
+	return DefaultConfig.SourceOf(path)
+}
+
+// Masked 获取脱敏后的配置
+func Masked() map[string]interface{} {
+	return DefaultConfig.Masked()
+}
+
+// History 获取配置快照
+func History() []*Snapshot {
+	return DefaultConfig.History()
+}
+
+// Rollback 回滚到指定校验和的配置快照
+func Rollback(checksum string, opts ...RollbackOption) error {
+	return DefaultConfig.Rollback(checksum, opts...)
+}
+
+// AddValidator 添加配置路径的校验方法
+func AddValidator(path string, fn ValidateFunc) {
+	DefaultConfig.AddValidator(path, fn)
+}
+
+// AddSchema 添加配置路径的JSON Schema校验
+func AddSchema(path string, schema []byte) error {
+	return DefaultConfig.AddSchema(path, schema)
+}
+
+// OnReject 添加配置重新加载被拒绝时的回调
+func OnReject(fn RejectFunc) {
+	DefaultConfig.OnReject(fn)
 }
 
 // LoadContent 直接加载byte数据
@@ -55,3 +95,18 @@ func Watch() {
 func UnWatch() {
 	DefaultConfig.UnWatch()
 }
+
+// WatchSource 只监听指定的配置源
+func WatchSource(source Source) {
+	DefaultConfig.WatchSource(source)
+}
+
+// UnWatchSource 取消监听指定的配置源
+func UnWatchSource(source Source) {
+	DefaultConfig.UnWatchSource(source)
+}
+
+// Write 将Set设置的值写回配置源
+func Write() error {
+	return DefaultConfig.Write()
+}
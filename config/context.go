@@ -0,0 +1,36 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package config
+
+import "context"
+
+// contextKey 上下文中保存配置管理器的key
+type contextKey struct{}
+
+// NewContext 返回携带配置管理器的上下文
+func NewContext(ctx context.Context, c Config) context.Context {
+	return context.WithValue(ctx, contextKey{}, c)
+}
+
+// FromContext 从上下文中获取配置管理器，不存在时返回DefaultConfig
+func FromContext(ctx context.Context) Config {
+	if ctx != nil {
+		if c, ok := ctx.Value(contextKey{}).(Config); ok && c != nil {
+			return c
+		}
+	}
+	return DefaultConfig
+}
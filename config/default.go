@@ -18,133 +18,538 @@ package config
 import (
 	"encoding/json"
 	"errors"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
+// contentSource Load直接加载的内容在SourceOf中显示的名称
+const contentSource = "content"
+
+// runtimeSource Set设置的值在SourceOf中显示的名称
+const runtimeSource = "runtime"
+
 type config struct {
-	mu        sync.RWMutex
-	store     *JSONValues
-	source    Source
-	onChanges []ChangeFunc
+	mu          sync.RWMutex
+	store       *JSONValues
+	layers      []*layer               // 配置层，按优先级从低到高排列
+	overrides   map[string]interface{} // Set设置的值，优先级最高
+	watching    bool
+	onChanges   []ChangeFunc
+	secrets     map[string]bool // 解密过的配置路径，输出配置时脱敏
+	notified    *JSONValues     // 最近一次通知时的配置，用于比较路径是否变化
+	watchers    []*pathWatcher  // 配置路径监听
+	validators  []validator     // 配置校验
+	onRejects   []RejectFunc    // 配置重新加载被拒绝时的回调
+	history     []*Snapshot     // 配置快照，按生效时间从早到晚排列
+	historySize int             // 保留的配置快照数量
+	keyring     Keyring         // 解密配置的密钥环，为nil时使用DefaultKeyring
+}
+
+// pathWatcher 配置路径监听
+type pathWatcher struct {
+	path string
+	fn   PathChangeFunc
 }
 
 func (c *config) Get(path string) Value {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.store.Get(path)
 }
 
 func (c *config) Root() Values {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	return c.store
 }
 
 func (c *config) Set(path string, data interface{}) error {
-	JSON, err := json.Marshal(data)
+	value, err := normalize(data)
 	if err != nil {
 		return err
 	}
-	var v interface{}
-	err = json.Unmarshal(JSON, &v)
-	if err != nil {
+	c.mu.Lock()
+	overrides := deepCopy(c.overrides)
+	deepMerge(c.overrides, nest(splitPath(path), value))
+	if err := c.merge(); err != nil {
+		c.overrides = overrides
+		c.mu.Unlock()
 		return err
 	}
-	m, ok := v.(map[string]interface{})
-	if ok {
-		err := c.traverse(m, []string{path}, func(p string, value interface{}) error {
-			c.store.Set(p, value)
-			return nil
-		})
-		if err != nil {
-			return err
-		}
-	} else {
-		c.store.Set(path, data)
-	}
+	c.mu.Unlock()
 	c.notifyChange()
 	return nil
 }
 
 func (c *config) OnChange(change ChangeFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	c.onChanges = append(c.onChanges, change)
 }
 
-func (c *config) LoadSource(source Source) error {
-	c.source = source
+// WatchPath 添加一个配置路径监听，只有该路径下的配置变化时才会回调，路径为空时监听全部配置，
+// 返回取消监听的方法
+func (c *config) WatchPath(path string, fn PathChangeFunc) func() {
+	w := &pathWatcher{path: path, fn: fn}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.watchers = append(c.watchers, w)
+	return func() {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, watcher := range c.watchers {
+			if watcher == w {
+				c.watchers = append(c.watchers[:i:i], c.watchers[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Masked 获取脱敏后的配置，敏感键名和解密过的值都会被脱敏
+func (c *config) Masked() map[string]interface{} {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	masked := MaskSecrets(c.store.Map())
+	for path := range c.secrets {
+		paths := splitPath(path)
+		parent, ok := lookup(masked, paths[:len(paths)-1])
+		if !ok {
+			continue
+		}
+		if m, ok := parent.(map[string]interface{}); ok {
+			m[paths[len(paths)-1]] = MaskValue
+		}
+	}
+	return integral(masked).(map[string]interface{})
+}
+
+// LoadSource 加载配置源并叠加到已有配置之上，默认后加载的配置源优先级更高
+func (c *config) LoadSource(source Source, opts ...SourceOption) error {
 	dataSet, redErr := source.Read()
 	if redErr != nil {
 		return redErr
 	}
-	if err := c.Load(dataSet.Data, dataSet.Format); err != nil {
+	data, err := decode(dataSet.Data, dataSet.Format)
+	if err != nil {
+		return err
+	}
+	l := newLayer(source.String(), source, dataSet.Format, data, opts...)
+	c.mu.Lock()
+	if err := c.addLayer(l); err != nil {
+		c.mu.Unlock()
 		return err
 	}
+	c.record(l, dataSet)
+	watching := c.watching
+	c.mu.Unlock()
+	if watching {
+		c.watch(l)
+	}
 	return nil
 }
 
+// Load 直接加载内容并叠加到已有配置之上，再次加载时替换上一次加载的内容
 func (c *config) Load(content []byte, unmarshal string) error {
-	decode, ok := Unmarshals[unmarshal]
-	if !ok {
-		return errors.New("load err: No unmarshal method found")
-	}
-	dataMap := make(map[string]interface{})
-	if err := decode(content, &dataMap); err != nil {
+	data, err := decode(content, unmarshal)
+	if err != nil {
 		return err
 	}
-	if data, err := json.Marshal(dataMap); err != nil {
+	dataSet := &DataSet{Data: content, Format: unmarshal, Source: contentSource, Timestamp: time.Now()}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	l := c.contentLayer()
+	if l == nil {
+		l = newLayer(contentSource, nil, unmarshal, data)
+		if err := c.addLayer(l); err != nil {
+			return err
+		}
+		c.record(l, dataSet)
+		return nil
+	}
+	old, format := l.data, l.format
+	l.data, l.format = data, unmarshal
+	if err := c.merge(); err != nil {
+		l.data, l.format = old, format
 		return err
-	} else {
-		c.store = NewJSONValues(data)
+	}
+	// 与加载配置源一致，不通知变化
+	c.notified = c.store
+	c.record(l, dataSet)
+	return nil
+}
+
+// contentLayer 获取Load加载的配置层，不存在时返回nil，调用方需持有锁
+func (c *config) contentLayer() *layer {
+	for _, l := range c.layers {
+		if l.source == nil && l.name == contentSource {
+			return l
+		}
 	}
 	return nil
 }
 
+// SourceOf 获取提供该配置的配置源名称，不存在时返回空字符串
+func (c *config) SourceOf(path string) string {
+	paths := splitPath(path)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if _, ok := lookup(c.overrides, paths); ok {
+		return runtimeSource
+	}
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		if _, ok := lookup(c.layers[i].data, paths); ok {
+			return c.layers[i].name
+		}
+	}
+	return ""
+}
+
+// Watch 分别监听每个配置源，任一配置源变化时重新合并配置
 func (c *config) Watch() {
-	c.source.Watch()
-	go func() {
-		for range c.source.IsChanged() {
-			if dataSet, err := c.source.Read(); err == nil {
-				_ = c.Load(dataSet.Data, dataSet.Format)
-				c.notifyChange()
+	c.mu.Lock()
+	if c.watching {
+		c.mu.Unlock()
+		return
+	}
+	c.watching = true
+	layers := make([]*layer, len(c.layers))
+	copy(layers, c.layers)
+	c.mu.Unlock()
+	for _, l := range layers {
+		c.watch(l)
+	}
+}
+
+// WatchSource 只监听指定的配置源，配置源需要已经通过LoadSource加载
+func (c *config) WatchSource(source Source) {
+	if l := c.layerOf(source); l != nil {
+		c.watch(l)
+	}
+}
+
+// UnWatchSource 取消监听指定的配置源
+func (c *config) UnWatchSource(source Source) {
+	if l := c.layerOf(source); l != nil {
+		c.unwatch(l)
+	}
+}
+
+// layerOf 获取配置源对应的配置层，不存在时返回nil
+func (c *config) layerOf(source Source) *layer {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, l := range c.layers {
+		if l.source != nil && l.source == source {
+			return l
+		}
+	}
+	return nil
+}
+
+// watch 监听单个配置源，已经在监听时忽略
+func (c *config) watch(l *layer) {
+	if l.source == nil {
+		return
+	}
+	c.mu.Lock()
+	if l.watching {
+		c.mu.Unlock()
+		return
+	}
+	l.watching = true
+	c.mu.Unlock()
+	l.source.Watch()
+	changed := l.source.IsChanged()
+	// 监听失败的配置源没有变化通道
+	if changed == nil {
+		c.mu.Lock()
+		l.watching = false
+		c.mu.Unlock()
+		return
+	}
+	go func(changed <-chan struct{}) {
+		for range changed {
+			dataSet, err := l.source.Read()
+			if err != nil {
+				c.reject(l.name, err)
+				continue
+			}
+			data, err := decode(dataSet.Data, dataSet.Format)
+			if err != nil {
+				c.reject(l.name, err)
+				continue
 			}
+			checksum := dataSet.Checksum
+			if checksum == "" {
+				checksum = dataSet.Sum()
+			}
+			c.mu.Lock()
+			// 内容没有变化，例如写回配置源触发的变化
+			if checksum == l.checksum {
+				c.mu.Unlock()
+				continue
+			}
+			old, format := l.data, l.format
+			l.data, l.format = data, dataSet.Format
+			// 合并或校验失败时保留上一次的配置
+			if err := c.merge(); err != nil {
+				l.data, l.format = old, format
+				c.mu.Unlock()
+				c.reject(l.name, err)
+				continue
+			}
+			c.record(l, dataSet)
+			c.mu.Unlock()
+			c.notifyChange()
 		}
-	}()
+	}(changed)
 }
 
+// UnWatch 取消监听所有配置源
 func (c *config) UnWatch() {
-	c.source.UnWatch()
+	c.mu.Lock()
+	if !c.watching {
+		c.mu.Unlock()
+		return
+	}
+	c.watching = false
+	layers := make([]*layer, len(c.layers))
+	copy(layers, c.layers)
+	c.mu.Unlock()
+	for _, l := range layers {
+		c.unwatch(l)
+	}
 }
 
-func (c *config) Write() error {
-	return nil
+// unwatch 取消监听单个配置源
+func (c *config) unwatch(l *layer) {
+	c.mu.Lock()
+	if !l.watching {
+		c.mu.Unlock()
+		return
+	}
+	l.watching = false
+	c.mu.Unlock()
+	l.source.UnWatch()
 }
 
-func (c *config) traverse(m map[string]interface{}, paths []string, callback func(path string, value interface{}) error) error {
-	for k, v := range m {
-		val, ok := v.(map[string]interface{})
-		if !ok {
-			err := callback(strings.Join(append(paths, k), "."), v)
-			if err != nil {
+// Write 将Set设置的值写回配置源，每个值写入当前提供该值的配置源，没有时写入优先级最高的配置源，
+// 配置源只读时依次尝试下一个，写入后不再被其他配置源覆盖的值从运行时配置中移除
+func (c *config) Write() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	readOnly := make(map[*layer]bool)
+	written := make(map[string]bool)
+	for {
+		groups := make(map[*layer][][]string)
+		for _, paths := range leaves(c.overrides, nil) {
+			if written[strings.Join(paths, ".")] {
+				continue
+			}
+			l := c.writable(paths, readOnly)
+			if l == nil {
+				return ErrNoWritableSource
+			}
+			groups[l] = append(groups[l], paths)
+		}
+		retry := false
+		for _, l := range c.layers {
+			paths, ok := groups[l]
+			if !ok {
+				continue
+			}
+			if err := c.writeLayer(l, paths); err != nil {
+				if errors.Is(err, ErrReadOnly) {
+					readOnly[l] = true
+					retry = true
+					continue
+				}
 				return err
 			}
+			for _, p := range paths {
+				written[strings.Join(p, ".")] = true
+			}
+		}
+		if !retry {
+			return c.merge()
+		}
+	}
+}
+
+// writable 获取路径对应的值应该写入的配置层，调用方需持有锁
+func (c *config) writable(paths []string, readOnly map[*layer]bool) *layer {
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		l := c.layers[i]
+		if l.source == nil || readOnly[l] {
 			continue
 		}
-		err := c.traverse(val, append(paths, k), callback)
-		if err != nil {
-			return err
+		if _, ok := lookup(l.data, paths); ok {
+			return l
 		}
 	}
+	for i := len(c.layers) - 1; i >= 0; i-- {
+		if l := c.layers[i]; l.source != nil && !readOnly[l] {
+			return l
+		}
+	}
+	return nil
+}
+
+// writeLayer 将运行时配置中的值合并到配置层后写回配置源，调用方需持有锁
+func (c *config) writeLayer(l *layer, paths [][]string) error {
+	data := deepCopy(l.data)
+	for _, p := range paths {
+		v, _ := lookup(c.overrides, p)
+		deepMerge(data, nest(p, copyValue(v)))
+	}
+	b, err := encode(data, l.format)
+	if err != nil {
+		return err
+	}
+	dataSet := &DataSet{
+		Data:      b,
+		Format:    l.format,
+		Source:    l.name,
+		Timestamp: time.Now(),
+	}
+	dataSet.Checksum = dataSet.Sum()
+	if err := l.source.Write(dataSet); err != nil {
+		return err
+	}
+	l.data, l.checksum = data, dataSet.Checksum
+	for _, p := range paths {
+		if !c.shadowed(l, p) {
+			remove(c.overrides, p)
+		}
+	}
+	return nil
+}
+
+// shadowed 判断路径对应的值是否被优先级更高的配置层覆盖，调用方需持有锁
+func (c *config) shadowed(l *layer, paths []string) bool {
+	for i := len(c.layers) - 1; i >= 0 && c.layers[i] != l; i-- {
+		if _, ok := lookup(c.layers[i].data, paths); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// addLayer 按优先级添加配置层并重新合并，合并失败时不添加，调用方需持有锁
+func (c *config) addLayer(l *layer) error {
+	layers := c.layers
+	c.layers = append(append(make([]*layer, 0, len(layers)+1), layers...), l)
+	sort.SliceStable(c.layers, func(i, j int) bool {
+		return c.layers[i].priority < c.layers[j].priority
+	})
+	if err := c.merge(); err != nil {
+		c.layers = layers
+		return err
+	}
+	// 加载配置源不通知变化，作为之后比较的基准
+	c.notified = c.store
+	return nil
+}
+
+// merge 按优先级从低到高合并所有配置层并解析占位符，失败时保留原配置，调用方需持有锁
+func (c *config) merge() error {
+	merged := make(map[string]interface{})
+	for _, l := range c.layers {
+		deepMerge(merged, deepCopy(l.data))
+	}
+	deepMerge(merged, deepCopy(c.overrides))
+	if err := interpolate(merged); err != nil {
+		return err
+	}
+	secrets, err := decryptValues(c.getKeyring(), merged)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	store := NewJSONValues(data)
+	if err := c.validate(store); err != nil {
+		return err
+	}
+	c.store = store
+	c.secrets = secrets
 	return nil
 }
 
 // 通知监听，配置文件已经被改过了
 func (c *config) notifyChange() {
-	for _, change := range c.onChanges {
-		change(c.store)
+	c.mu.Lock()
+	old, store := c.notified, c.store
+	c.notified = store
+	changes := make([]ChangeFunc, len(c.onChanges))
+	copy(changes, c.onChanges)
+	watchers := make([]*pathWatcher, len(c.watchers))
+	copy(watchers, c.watchers)
+	c.mu.Unlock()
+	for _, change := range changes {
+		change(store)
+	}
+	for _, w := range watchers {
+		oldValue, newValue := old.Get(w.path), store.Get(w.path)
+		if !equalValue(oldValue, newValue) {
+			w.fn(oldValue, newValue)
+		}
 	}
 }
 
+// decode 解码配置内容
+func decode(content []byte, unmarshal string) (map[string]interface{}, error) {
+	fn, ok := Unmarshals[unmarshal]
+	if !ok {
+		return nil, errors.New("load err: No unmarshal method found")
+	}
+	dataMap := make(map[string]interface{})
+	if err := fn(content, &dataMap); err != nil {
+		return nil, err
+	}
+	value, err := normalize(dataMap)
+	if err != nil {
+		return nil, err
+	}
+	if m, ok := value.(map[string]interface{}); ok {
+		return m, nil
+	}
+	return make(map[string]interface{}), nil
+}
+
+// encode 编码配置内容
+func encode(data map[string]interface{}, format string) ([]byte, error) {
+	fn, ok := Marshals[format]
+	if !ok {
+		return nil, errors.New("write err: No marshal method found")
+	}
+	return fn(integral(data))
+}
+
+// splitPath 分割配置路径
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
 // NewConfig 创建一个新的config管理器
-func NewConfig() Config {
+func NewConfig(opts ...Option) Config {
+	store := NewJSONValues([]byte("{}"))
 	conf := config{
-		store: NewJSONValues([]byte("")),
+		store:       store,
+		notified:    store,
+		overrides:   make(map[string]interface{}),
+		historySize: DefaultHistorySize,
+	}
+	for _, opt := range opts {
+		opt(&conf)
 	}
 	return &conf
 }
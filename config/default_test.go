@@ -0,0 +1,228 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+// memorySource 测试用的内存配置源
+type memorySource struct {
+	name     string
+	data     string
+	readOnly bool
+	changed  chan struct{}
+}
+
+func newMemorySource(name, data string) *memorySource {
+	return &memorySource{name: name, data: data}
+}
+
+func (m *memorySource) Read() (*DataSet, error) {
+	return &DataSet{Data: []byte(m.data), Format: "json", Source: m.name}, nil
+}
+
+func (m *memorySource) Write(ds *DataSet) error {
+	if m.readOnly {
+		return ErrReadOnly
+	}
+	m.data = string(ds.Data)
+	return nil
+}
+
+func (m *memorySource) IsChanged() <-chan struct{} { return m.changed }
+
+func (m *memorySource) Watch() { m.changed = make(chan struct{}, 1) }
+
+func (m *memorySource) UnWatch() { close(m.changed) }
+
+func (m *memorySource) String() string { return m.name }
+
+func TestConfigLayers(t *testing.T) {
+	c := NewConfig()
+	base := newMemorySource("base", `{"app":{"name":"base","port":80,"tags":["a"]}}`)
+	env := newMemorySource("env", `{"app":{"port":8080}}`)
+	remote := newMemorySource("remote", `{"app":{"name":"remote","port":9090}}`)
+	if err := c.LoadSource(base); err != nil {
+		t.Fatal(err)
+	}
+	// 优先级最高，虽然先于env加载
+	if err := c.LoadSource(remote, SourcePriority(10)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadSource(env); err != nil {
+		t.Fatal(err)
+	}
+	if name := c.Get("app.name").String(""); name != "remote" {
+		t.Fatalf("app.name = %s, want remote", name)
+	}
+	if port := c.Get("app.port").Int(0); port != 9090 {
+		t.Fatalf("app.port = %d, want 9090", port)
+	}
+	if tags := c.Get("app.tags").StringSlice(nil); len(tags) != 1 || tags[0] != "a" {
+		t.Fatalf("app.tags = %v", tags)
+	}
+	for path, want := range map[string]string{"app.name": "remote", "app.tags": "base", "app.missing": ""} {
+		if got := c.SourceOf(path); got != want {
+			t.Fatalf("SourceOf(%s) = %s, want %s", path, got, want)
+		}
+	}
+	if err := c.Set("app.port", 1); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.SourceOf("app.port"); got != runtimeSource {
+		t.Fatalf("SourceOf(app.port) = %s, want %s", got, runtimeSource)
+	}
+}
+
+func TestConfigLoadReplace(t *testing.T) {
+	c := NewConfig()
+	if err := c.Load([]byte(`{"app":{"name":"demo","debug":true}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Load([]byte(`{"app":{"name":"demo2"}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if name := c.Get("app.name").String(""); name != "demo2" {
+		t.Fatalf("app.name = %s, want demo2", name)
+	}
+	// 再次加载替换上一次的内容，删除的配置项不再保留
+	if !c.Get("app.debug").IsEmpty() {
+		t.Fatal("app.debug should be removed")
+	}
+	if n := len(c.(*config).layers); n != 1 {
+		t.Fatalf("layers = %d, want 1", n)
+	}
+}
+
+func TestConfigWatchLayers(t *testing.T) {
+	c := NewConfig()
+	base := newMemorySource("base", `{"app":{"name":"base","port":80}}`)
+	env := newMemorySource("env", `{"app":{"port":8080}}`)
+	_ = c.LoadSource(base)
+	_ = c.LoadSource(env)
+	changed := make(chan Values, 1)
+	c.OnChange(func(v Values) {
+		changed <- v
+	})
+	c.Watch()
+	defer c.UnWatch()
+
+	base.data = `{"app":{"name":"changed","port":81}}`
+	base.changed <- struct{}{}
+	select {
+	case v := <-changed:
+		if name := v.Get("app.name").String(""); name != "changed" {
+			t.Fatalf("app.name = %s, want changed", name)
+		}
+		if port := v.Get("app.port").Int(0); port != 8080 {
+			t.Fatalf("app.port = %d, want 8080", port)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("change not notified")
+	}
+}
+
+func TestConfigWatchSource(t *testing.T) {
+	c := NewConfig()
+	base := newMemorySource("base", `{"app":{"name":"base"}}`)
+	env := newMemorySource("env", `{"app":{"port":8080}}`)
+	_ = c.LoadSource(base)
+	_ = c.LoadSource(env)
+	c.WatchSource(base)
+	if base.changed == nil || env.changed != nil {
+		t.Fatal("only base should be watched")
+	}
+	// 已经监听的配置源不重复监听
+	watched := base.changed
+	c.Watch()
+	if base.changed != watched || env.changed == nil {
+		t.Fatal("Watch should only watch sources not yet watched")
+	}
+	// 已经取消监听的配置源不重复取消，重复关闭通道会panic
+	c.UnWatchSource(base)
+	c.UnWatch()
+}
+
+func TestConfigWrite(t *testing.T) {
+	c := NewConfig()
+	base := newMemorySource("base", `{"app":{"name":"base","port":80}}`)
+	remote := newMemorySource("remote", `{"app":{"host":"${HOST:localhost}"}}`)
+	env := newMemorySource("env", `{"app":{"debug":false}}`)
+	env.readOnly = true
+	_ = c.LoadSource(base)
+	_ = c.LoadSource(remote)
+	_ = c.LoadSource(env)
+	_ = c.Set("app.port", 8080)
+	_ = c.Set("app.debug", true)
+	_ = c.Set("app.tags", []string{"a"})
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+	// 已存在的值写回提供该值的配置源，新值和只读配置源的值写入优先级最高的可写配置源
+	if want := `{
+  "app": {
+    "name": "base",
+    "port": 8080
+  }
+}`; base.data != want {
+		t.Fatalf("base = %s, want %s", base.data, want)
+	}
+	if want := `{
+  "app": {
+    "debug": true,
+    "host": "${HOST:localhost}",
+    "tags": [
+      "a"
+    ]
+  }
+}`; remote.data != want {
+		t.Fatalf("remote = %s, want %s", remote.data, want)
+	}
+	// 被只读配置源覆盖的值仍保留在运行时配置中
+	for path, want := range map[string]string{"app.port": "base", "app.tags": "remote", "app.debug": runtimeSource} {
+		if got := c.SourceOf(path); got != want {
+			t.Fatalf("SourceOf(%s) = %s, want %s", path, got, want)
+		}
+	}
+	if port := c.Get("app.port").Int(0); port != 8080 {
+		t.Fatalf("app.port = %d, want 8080", port)
+	}
+
+	c = NewConfig()
+	_ = c.LoadSource(env)
+	_ = c.Set("app.port", 1)
+	if err := c.Write(); err != ErrNoWritableSource {
+		t.Fatalf("Write() = %v, want %v", err, ErrNoWritableSource)
+	}
+}
+
+func TestWatchPathCancel(t *testing.T) {
+	c := NewConfig()
+	_ = c.Load([]byte(`{"a":1,"b":1}`), "json")
+	var a, b int
+	cancelA := c.WatchPath("a", func(_, _ Value) { a++ })
+	c.WatchPath("b", func(_, _ Value) { b++ })
+	_ = c.Set("a", 2)
+	cancelA()
+	cancelA()
+	_ = c.Set("a", 3)
+	_ = c.Set("b", 2)
+	if a != 1 || b != 1 {
+		t.Fatalf("a = %d, b = %d, want 1, 1", a, b)
+	}
+}
@@ -0,0 +1,91 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"sort"
+)
+
+// Diff 两份配置之间的差异，只记录发生变化的配置路径，不记录值，避免敏感信息出现在日志中
+type Diff struct {
+	Added    []string `json:"added,omitempty"`    // 新增的配置
+	Removed  []string `json:"removed,omitempty"`  // 删除的配置
+	Modified []string `json:"modified,omitempty"` // 修改的配置
+}
+
+// IsEmpty 是否没有变化
+func (d *Diff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Modified) == 0
+}
+
+// Compare 比较两份配置，返回以叶子节点路径表示的差异，列表视为一个整体
+func Compare(old, new Value) *Diff {
+	oldLeaves, newLeaves := flatten(old), flatten(new)
+	diff := &Diff{}
+	for path, v := range newLeaves {
+		ov, ok := oldLeaves[path]
+		if !ok {
+			diff.Added = append(diff.Added, path)
+		} else if !reflect.DeepEqual(ov, v) {
+			diff.Modified = append(diff.Modified, path)
+		}
+	}
+	for path := range oldLeaves {
+		if _, ok := newLeaves[path]; !ok {
+			diff.Removed = append(diff.Removed, path)
+		}
+	}
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+	sort.Strings(diff.Modified)
+	return diff
+}
+
+// flatten 将配置展开为叶子节点路径到值的映射
+func flatten(v Value) map[string]interface{} {
+	res := make(map[string]interface{})
+	if v == nil || v.IsEmpty() {
+		return res
+	}
+	var data interface{}
+	if err := v.Scan(&data); err != nil {
+		return res
+	}
+	flattenValue("", data, res)
+	return res
+}
+
+// flattenValue 递归展开配置，空map视为叶子节点
+func flattenValue(prefix string, data interface{}, res map[string]interface{}) {
+	m, ok := data.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		res[prefix] = data
+		return
+	}
+	for k, v := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		flattenValue(path, v, res)
+	}
+}
+
+// equalValue 判断两个配置值是否相同
+func equalValue(a, b Value) bool {
+	return reflect.DeepEqual(flatten(a), flatten(b))
+}
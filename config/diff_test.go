@@ -0,0 +1,63 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	old := NewJSONValues([]byte(`{"app":{"name":"a","port":80,"tags":["x"]},"log":{"level":"info"}}`))
+	new := NewJSONValues([]byte(`{"app":{"name":"a","port":81,"tags":["x","y"],"debug":true},"db":{}}`))
+	diff := Compare(old.Get(""), new.Get(""))
+	want := &Diff{
+		Added:    []string{"app.debug", "db"},
+		Removed:  []string{"log.level"},
+		Modified: []string{"app.port", "app.tags"},
+	}
+	if !reflect.DeepEqual(diff, want) {
+		t.Fatalf("Compare() = %+v, want %+v", diff, want)
+	}
+	if !Compare(old.Get("log"), old.Get("log")).IsEmpty() {
+		t.Fatal("same value should be empty diff")
+	}
+}
+
+func TestConfigWatchPath(t *testing.T) {
+	c := NewConfig()
+	_ = c.Load([]byte(`{"app":{"name":"a","port":80},"log":{"level":"info"}}`), "json")
+	type change struct{ old, new string }
+	var appChanges, levelChanges []change
+	c.WatchPath("app", func(old, new Value) {
+		appChanges = append(appChanges, change{string(old.Bytes()), string(new.Bytes())})
+	})
+	c.WatchPath("log.level", func(old, new Value) {
+		levelChanges = append(levelChanges, change{old.String(""), new.String("")})
+	})
+	_ = c.Set("log.level", "debug")
+	_ = c.Set("log.level", "debug")
+	if len(appChanges) != 0 {
+		t.Fatalf("app changes = %v, want none", appChanges)
+	}
+	if want := []change{{"info", "debug"}}; !reflect.DeepEqual(levelChanges, want) {
+		t.Fatalf("level changes = %v, want %v", levelChanges, want)
+	}
+	_ = c.Set("app.port", 81)
+	if want := []change{{`{"name":"a","port":80}`, `{"name":"a","port":81}`}}; !reflect.DeepEqual(appChanges, want) {
+		t.Fatalf("app changes = %v, want %v", appChanges, want)
+	}
+}
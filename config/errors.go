@@ -18,4 +18,10 @@ package config
 import "errors"
 
 // ErrInvalidKey ...
-var ErrInvalidKey = errors.New("invalid key, maybe not exist in config")
+var (
+	ErrInvalidKey = errors.New("invalid key, maybe not exist in config")
+	// ErrReadOnly 配置源不支持写入
+	ErrReadOnly = errors.New("config source is read only")
+	// ErrNoWritableSource 没有可以写入的配置源
+	ErrNoWritableSource = errors.New("no writable config source")
+)
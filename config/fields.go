@@ -0,0 +1,224 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+)
+
+// structField 结构体中按json编解码的字段
+type structField struct {
+	name  string       // json中的键名
+	typ   reflect.Type // 字段类型
+	usage string       // 字段说明，来自usage标签
+}
+
+// structFields 获取结构体按json编解码的字段，匿名嵌入的结构体字段会被展开
+func structFields(t reflect.Type) []structField {
+	res := make([]structField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				res = append(res, structFields(ft)...)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		res = append(res, structField{name: name, typ: f.Type, usage: f.Tag.Get("usage")})
+	}
+	return res
+}
+
+// convertValues 按目标类型递归转换数据中的值，fn先于子节点在每个节点上调用，自定义解码的类型不处理
+func convertValues(data interface{}, t reflect.Type, fn func(v interface{}, t reflect.Type) interface{}) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if reflect.PtrTo(t).Implements(unmarshalerType) {
+		return data
+	}
+	data = fn(data, t)
+	if t == durationType {
+		return data
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := data.(map[string]interface{})
+		if !ok {
+			return data
+		}
+		fields := structFields(t)
+		for k, v := range m {
+			// 与encoding/json一致，键名不区分大小写
+			for _, f := range fields {
+				if strings.EqualFold(f.name, k) {
+					m[k] = convertValues(v, f.typ, fn)
+					break
+				}
+			}
+		}
+	case reflect.Map:
+		if m, ok := data.(map[string]interface{}); ok {
+			for k, v := range m {
+				m[k] = convertValues(v, t.Elem(), fn)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		if list, ok := data.([]interface{}); ok {
+			for i, v := range list {
+				list[i] = convertValues(v, t.Elem(), fn)
+			}
+		}
+	}
+	return data
+}
+
+// coerceValue 按目标类型转换字符串，使环境变量、占位符等只能提供字符串的配置可以填充
+// 数字、布尔值、时间间隔和列表字段，列表以逗号分隔，目标为字符串时保持原值
+func coerceValue(v interface{}, t reflect.Type) interface{} {
+	s, ok := v.(string)
+	if !ok {
+		return v
+	}
+	if t == durationType {
+		return parseDuration(s)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, err := strconv.ParseBool(strings.TrimSpace(s)); err == nil {
+			return b
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		if n, ok := parseNumber(s); ok {
+			return n
+		}
+	case reflect.Slice, reflect.Array:
+		// []byte按base64字符串解码
+		if t.Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		list := make([]interface{}, 0)
+		if strings.TrimSpace(s) == "" {
+			return list
+		}
+		for _, part := range strings.Split(s, ",") {
+			list = append(list, strings.TrimSpace(part))
+		}
+		return list
+	}
+	return v
+}
+
+// parseNumber 解析json格式的数字
+func parseNumber(s string) (json.Number, bool) {
+	s = strings.TrimSpace(s)
+	var n json.Number
+	if s == "" || s[0] == '"' || json.Unmarshal([]byte(s), &n) != nil {
+		return "", false
+	}
+	return n, true
+}
+
+// parseDuration 将"3s"等时间间隔字符串转换为纳秒数，纯数字的字符串按纳秒数处理
+func parseDuration(v interface{}) interface{} {
+	if s, ok := v.(string); ok {
+		if d, err := time.ParseDuration(strings.TrimSpace(s)); err == nil {
+			return int64(d)
+		}
+		if n, ok := parseNumber(s); ok {
+			return n
+		}
+	}
+	return v
+}
+
+// formatDuration 将time.Duration类型字段的纳秒数转换为"3s"等时间间隔字符串
+func formatDuration(v interface{}, t reflect.Type) interface{} {
+	if t != durationType {
+		return v
+	}
+	switch val := v.(type) {
+	case float64:
+		return time.Duration(val).String()
+	case json.Number:
+		if n, err := val.Int64(); err == nil {
+			return time.Duration(n).String()
+		}
+	}
+	return v
+}
+
+// scan 解码配置到v，按目标字段类型转换字符串，time.Duration类型的字段同时支持纳秒数和"3s"等字符串
+func scan(b []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return err
+	}
+	if t := reflect.TypeOf(v); t != nil {
+		data = convertValues(data, t, coerceValue)
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
+
+// fieldUsages 获取结构体各字段路径对应的说明，depth限制嵌套的层数，避免递归类型无限展开
+func fieldUsages(t reflect.Type, prefix string, depth int, res map[string]string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || depth <= 0 {
+		return
+	}
+	for _, f := range structFields(t) {
+		path := prefix + "." + f.name
+		if f.usage != "" {
+			res[path] = f.usage
+		}
+		fieldUsages(f.typ, path, depth-1, res)
+	}
+}
@@ -0,0 +1,174 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"time"
+)
+
+// DefaultHistorySize 默认保留的配置快照数量
+const DefaultHistorySize = 20
+
+// ErrSnapshotNotFound 快照不存在或对应的配置源已经不在配置中
+var ErrSnapshotNotFound = errors.New("config snapshot not found")
+
+// Option 配置管理器选项
+type Option func(c *config)
+
+// HistorySize 设置保留的配置快照数量，小于等于0时不保留
+func HistorySize(size int) Option {
+	return func(c *config) {
+		c.historySize = size
+	}
+}
+
+// Snapshot 配置源每次生效的数据快照
+type Snapshot struct {
+	Checksum  string    `json:"checksum"`  // 数据校验和
+	Source    string    `json:"source"`    // 配置源名称
+	Format    string    `json:"format"`    // 数据格式
+	Timestamp time.Time `json:"timestamp"` // 生效时间
+	Data      []byte    `json:"-"`         // 原始数据
+	layer     *layer
+}
+
+// RollbackOption 回滚选项
+type RollbackOption func(o *rollbackOptions)
+
+// rollbackOptions 回滚选项
+type rollbackOptions struct {
+	write bool
+}
+
+// RollbackWrite 回滚时同时将快照写回配置源
+func RollbackWrite() RollbackOption {
+	return func(o *rollbackOptions) {
+		o.write = true
+	}
+}
+
+// History 获取配置快照，按生效时间从早到晚排列
+func (c *config) History() []*Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	res := make([]*Snapshot, len(c.history))
+	for i, s := range c.history {
+		snapshot := *s
+		res[i] = &snapshot
+	}
+	return res
+}
+
+// Rollback 重新应用指定校验和的快照并通知配置变化，多个快照校验和相同时使用最近的一个
+func (c *config) Rollback(checksum string, opts ...RollbackOption) error {
+	o := &rollbackOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	c.mu.Lock()
+	var snapshot *Snapshot
+	for i := len(c.history) - 1; i >= 0; i-- {
+		if c.history[i].Checksum == checksum {
+			snapshot = c.history[i]
+			break
+		}
+	}
+	if snapshot == nil || !c.hasLayer(snapshot.layer) {
+		c.mu.Unlock()
+		return ErrSnapshotNotFound
+	}
+	l := snapshot.layer
+	data, err := decode(snapshot.Data, snapshot.Format)
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	dataSet := &DataSet{
+		Data:      snapshot.Data,
+		Checksum:  snapshot.Checksum,
+		Format:    snapshot.Format,
+		Source:    snapshot.Source,
+		Timestamp: time.Now(),
+	}
+	if o.write {
+		if l.source == nil {
+			c.mu.Unlock()
+			return ErrReadOnly
+		}
+		if err := l.source.Write(dataSet); err != nil {
+			c.mu.Unlock()
+			return err
+		}
+	}
+	old, format := l.data, l.format
+	l.data, l.format = data, snapshot.Format
+	if err := c.merge(); err != nil {
+		l.data, l.format = old, format
+		c.mu.Unlock()
+		return err
+	}
+	c.record(l, dataSet)
+	c.mu.Unlock()
+	c.notifyChange()
+	return nil
+}
+
+// record 记录配置源生效的快照，超出数量时丢弃最早的快照，调用方需持有锁
+func (c *config) record(l *layer, dataSet *DataSet) {
+	checksum := dataSet.Checksum
+	if checksum == "" {
+		checksum = dataSet.Sum()
+	}
+	l.checksum = checksum
+	if c.historySize <= 0 {
+		return
+	}
+	// 配置源内容没有变化时不重复记录
+	for i := len(c.history) - 1; i >= 0; i-- {
+		if c.history[i].layer == l {
+			if c.history[i].Checksum == checksum {
+				return
+			}
+			break
+		}
+	}
+	timestamp := dataSet.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	c.history = append(c.history, &Snapshot{
+		Checksum:  checksum,
+		Source:    l.name,
+		Format:    dataSet.Format,
+		Timestamp: timestamp,
+		Data:      dataSet.Data,
+		layer:     l,
+	})
+	if n := len(c.history) - c.historySize; n > 0 {
+		c.history = append(c.history[:0:0], c.history[n:]...)
+	}
+}
+
+// hasLayer 判断配置层是否还在配置中，调用方需持有锁
+func (c *config) hasLayer(l *layer) bool {
+	for _, item := range c.layers {
+		if item == l {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestConfigRollback(t *testing.T) {
+	c := NewConfig(HistorySize(4))
+	remote := newMemorySource("remote", `{"app":{"port":80}}`)
+	_ = c.Load([]byte(`{"app":{"name":"demo"}}`), "json")
+	_ = c.LoadSource(remote)
+	changed := make(chan Values, 1)
+	c.OnChange(func(v Values) {
+		changed <- v
+	})
+	c.Watch()
+	defer c.UnWatch()
+
+	good := c.History()[1]
+	if good.Source != "remote" || good.Checksum == "" {
+		t.Fatalf("snapshot = %+v", good)
+	}
+	for _, data := range []string{`{"app":{"port":-1}}`, `{"app":{"port":-2}}`, `{"app":{"port":-3}}`} {
+		remote.data = data
+		remote.changed <- struct{}{}
+		select {
+		case <-changed:
+		case <-time.After(time.Second):
+			t.Fatal("change not notified")
+		}
+	}
+	history := c.History()
+	// 超出数量时丢弃最早的快照
+	if len(history) != 4 || history[0].Checksum != good.Checksum {
+		t.Fatalf("history = %+v", history)
+	}
+	if err := c.Rollback("missing"); err != ErrSnapshotNotFound {
+		t.Fatalf("Rollback() = %v, want %v", err, ErrSnapshotNotFound)
+	}
+	if err := c.Rollback(good.Checksum, RollbackWrite()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case v := <-changed:
+		if port := v.Get("app.port").Int(0); port != 80 {
+			t.Fatalf("app.port = %d, want 80", port)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("rollback not notified")
+	}
+	if remote.data != `{"app":{"port":80}}` {
+		t.Fatalf("remote = %s", remote.data)
+	}
+	if name := c.Get("app.name").String(""); name != "demo" {
+		t.Fatalf("app.name = %s, want demo", name)
+	}
+	if history := c.History(); history[len(history)-1].Checksum != good.Checksum {
+		t.Fatalf("rollback should be recorded, history = %+v", history)
+	}
+}
@@ -21,16 +21,31 @@ import (
 
 type ChangeFunc func(v Values)
 
+// PathChangeFunc 配置路径变化回调，参数为变化前后该路径下的配置
+type PathChangeFunc func(old, new Value)
+
 type Config interface {
-	LoadSource(source Source) error
+	LoadSource(source Source, opts ...SourceOption) error
 	Load(content []byte, format string) error
 	Get(path string) Value
 	Root() Values
 	Set(Path string, data interface{}) error
 	OnChange(change ChangeFunc)
+	WatchPath(path string, fn PathChangeFunc) func()
 	UnWatch()
 	Watch()
+	WatchSource(source Source)
+	UnWatchSource(source Source)
 	Write() error
+	SourceOf(path string) string
+	Masked() map[string]interface{}
+	History() []*Snapshot
+	Rollback(checksum string, opts ...RollbackOption) error
+	AddValidator(path string, fn ValidateFunc)
+	AddSchema(path string, schema []byte) error
+	OnReject(fn RejectFunc)
+	SetKeyring(keyring Keyring)
+	Keyring() Keyring
 }
 type Values interface {
 	Get(path string) Value
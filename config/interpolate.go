@@ -0,0 +1,193 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// interpolator 解析配置中的占位符：
+// ${ENV_VAR:default} 读取环境变量，不存在时读取同名配置，再不存在时使用默认值；
+// ${a.b.c:default} 引用其他配置，不存在时使用默认值；
+// $${...} 转义为字面量${...}
+type interpolator struct {
+	root      map[string]interface{}
+	resolved  map[string]bool // 已解析的路径
+	resolving []string        // 正在解析的路径，用于检测循环引用
+	lookupEnv func(key string) (string, bool)
+}
+
+// interpolate 解析所有占位符，直接修改传入的数据
+func interpolate(root map[string]interface{}) error {
+	i := &interpolator{
+		root:      root,
+		resolved:  make(map[string]bool),
+		lookupEnv: os.LookupEnv,
+	}
+	return i.resolveMap(root, "")
+}
+
+// resolveMap 解析map中的所有值
+func (i *interpolator) resolveMap(m map[string]interface{}, prefix string) error {
+	for k := range m {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if _, err := i.resolvePath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolvePath 解析指定路径的值并写回
+func (i *interpolator) resolvePath(path string) (interface{}, error) {
+	paths := splitPath(path)
+	raw, ok := lookup(i.root, paths)
+	if !ok || i.resolved[path] {
+		return raw, nil
+	}
+	for idx, p := range i.resolving {
+		if p == path {
+			return nil, fmt.Errorf("config: placeholder cycle %s", strings.Join(append(i.resolving[idx:], path), " -> "))
+		}
+	}
+	i.resolving = append(i.resolving, path)
+	defer func() {
+		i.resolving = i.resolving[:len(i.resolving)-1]
+	}()
+	var res interface{}
+	switch val := raw.(type) {
+	case string:
+		v, err := i.expand(val, path)
+		if err != nil {
+			return nil, err
+		}
+		res = v
+	case map[string]interface{}:
+		if err := i.resolveMap(val, path); err != nil {
+			return nil, err
+		}
+		res = val
+	case []interface{}:
+		for idx, item := range val {
+			s, ok := item.(string)
+			if !ok {
+				continue
+			}
+			v, err := i.expand(s, path)
+			if err != nil {
+				return nil, err
+			}
+			val[idx] = v
+		}
+		res = val
+	default:
+		res = val
+	}
+	setValue(i.root, paths, res)
+	i.resolved[path] = true
+	return res, nil
+}
+
+// expand 替换字符串中的占位符，整个字符串只有一个占位符时保留引用值的类型
+func (i *interpolator) expand(s string, path string) (interface{}, error) {
+	if !strings.Contains(s, "${") {
+		return s, nil
+	}
+	if strings.HasPrefix(s, "${") && strings.Index(s, "}") == len(s)-1 {
+		return i.placeholder(s[2:len(s)-1], path)
+	}
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "${")
+		if start < 0 {
+			b.WriteString(s)
+			break
+		}
+		// $${ 转义
+		if start > 0 && s[start-1] == '$' {
+			b.WriteString(s[:start-1])
+			b.WriteString("${")
+			s = s[start+2:]
+			continue
+		}
+		end := strings.Index(s[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("config: unclosed placeholder in %s", path)
+		}
+		b.WriteString(s[:start])
+		v, err := i.placeholder(s[start+2:start+end], path)
+		if err != nil {
+			return nil, err
+		}
+		b.WriteString(stringify(v))
+		s = s[start+end+1:]
+	}
+	return b.String(), nil
+}
+
+// placeholder 解析单个占位符，环境变量和默认值保持字符串，解码时再按目标字段类型转换
+func (i *interpolator) placeholder(expr string, path string) (interface{}, error) {
+	name, def, hasDef := expr, "", false
+	if idx := strings.Index(expr, ":"); idx >= 0 {
+		name, def, hasDef = expr[:idx], expr[idx+1:], true
+	}
+	name = strings.TrimSpace(name)
+	if !strings.Contains(name, ".") {
+		if v, ok := i.lookupEnv(name); ok {
+			return v, nil
+		}
+	}
+	if _, ok := lookup(i.root, splitPath(name)); ok && name != "" {
+		return i.resolvePath(name)
+	}
+	if hasDef {
+		return def, nil
+	}
+	return nil, fmt.Errorf("config: unresolved placeholder ${%s} in %s", expr, path)
+}
+
+// setValue 按路径写入值，路径必须已存在
+func setValue(root map[string]interface{}, paths []string, value interface{}) {
+	if len(paths) == 0 {
+		return
+	}
+	parent, ok := lookup(root, paths[:len(paths)-1])
+	if !ok {
+		return
+	}
+	if m, ok := parent.(map[string]interface{}); ok {
+		m[paths[len(paths)-1]] = value
+	}
+}
+
+// stringify 将引用的值转为字符串
+func stringify(v interface{}) string {
+	switch val := v.(type) {
+	case string:
+		return val
+	case map[string]interface{}, []interface{}:
+		b, _ := json.Marshal(val)
+		return string(b)
+	default:
+		return fmt.Sprint(val)
+	}
+}
@@ -0,0 +1,133 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestInterpolate(t *testing.T) {
+	t.Setenv("CERES_TEST_REDIS_HOST", "10.0.0.1")
+	c := NewConfig()
+	err := c.Load([]byte(`{
+		"ceres": {
+			"application": {"name": "demo", "port": 6379},
+			"redis": {"addr": "${CERES_TEST_REDIS_HOST}:${ceres.application.port}"},
+			"client": {"redis": {"default": {"addrs": ["${ceres.redis.addr}"], "port": "${ceres.application.port}"}}},
+			"cache": {"redis": {"default": {"addrs": ["${ceres.redis.addr}"], "prefix": "${ceres.application.name}:${CERES_TEST_MISSING:cache}"}}},
+			"literal": "$${ceres.application.name}"
+		}
+	}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf struct {
+		Addrs []string `json:"addrs"`
+		Port  int      `json:"port"`
+	}
+	if err := c.Get("ceres.client.redis.default").Scan(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if len(conf.Addrs) != 1 || conf.Addrs[0] != "10.0.0.1:6379" || conf.Port != 6379 {
+		t.Fatalf("unexpected config %+v", conf)
+	}
+	if prefix := c.Get("ceres.cache.redis.default.prefix").String(""); prefix != "demo:cache" {
+		t.Fatalf("prefix = %s, want demo:cache", prefix)
+	}
+	if literal := c.Get("ceres.literal").String(""); literal != "${ceres.application.name}" {
+		t.Fatalf("literal = %s", literal)
+	}
+}
+
+func TestInterpolateScalar(t *testing.T) {
+	t.Setenv("CERES_TEST_PORT", "9090")
+	t.Setenv("CERES_TEST_NAME", "demo")
+	c := NewConfig()
+	err := c.Load([]byte(`
+[server]
+port = "${CERES_TEST_PORT:8080}"
+backlog = "${CERES_TEST_MISSING:128}"
+debug = "${CERES_TEST_MISSING:true}"
+ratio = "${CERES_TEST_MISSING:0.5}"
+name = "${CERES_TEST_NAME}"
+addr = "127.0.0.1:${CERES_TEST_MISSING:8080}"
+`), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf struct {
+		Port    int     `json:"port"`
+		Backlog int     `json:"backlog"`
+		Debug   bool    `json:"debug"`
+		Ratio   float64 `json:"ratio"`
+		Name    string  `json:"name"`
+		Addr    string  `json:"addr"`
+	}
+	if err := c.Get("server").Scan(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Port != 9090 || conf.Backlog != 128 || !conf.Debug || conf.Ratio != 0.5 || conf.Name != "demo" || conf.Addr != "127.0.0.1:8080" {
+		t.Fatalf("unexpected config %+v", conf)
+	}
+}
+
+func TestInterpolateNumericString(t *testing.T) {
+	t.Setenv("CERES_TEST_PASSWORD", "123456")
+	t.Setenv("CERES_TEST_VERSION", "1.10")
+	c := NewConfig()
+	err := c.Load([]byte(`
+[redis]
+password = "${CERES_TEST_PASSWORD}"
+zip = "${CERES_TEST_MISSING:01234}"
+version = "${CERES_TEST_VERSION}"
+db = "${CERES_TEST_MISSING:3}"
+`), "toml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var conf struct {
+		Password string `json:"password"`
+		Zip      string `json:"zip"`
+		Version  string `json:"version"`
+		DB       int    `json:"db"`
+	}
+	if err := c.Get("redis").Scan(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Password != "123456" || conf.Zip != "01234" || conf.Version != "1.10" || conf.DB != 3 {
+		t.Fatalf("unexpected config %+v", conf)
+	}
+}
+
+func TestInterpolateErrors(t *testing.T) {
+	c := NewConfig()
+	err := c.Load([]byte(`{"a": "${b.c}", "b": {"c": "${a}"}}`), "json")
+	if err == nil || !strings.Contains(err.Error(), "cycle") {
+		t.Fatalf("err = %v, want cycle error", err)
+	}
+	err = c.Load([]byte(`{"a": "${CERES_TEST_NOT_EXISTS}"}`), "json")
+	if err == nil || !strings.Contains(err.Error(), "unresolved") {
+		t.Fatalf("err = %v, want unresolved error", err)
+	}
+	// 加载失败不影响已有配置
+	if err := c.Load([]byte(`{"a": "ok"}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if a := c.Get("a").String(""); a != "ok" {
+		t.Fatalf("a = %s, want ok", a)
+	}
+}
@@ -0,0 +1,211 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package config
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// SourceOption 配置源选项
+type SourceOption func(l *layer)
+
+// SourcePriority 设置配置源优先级，数值越大越优先，相同优先级时后加载的优先
+func SourcePriority(priority int) SourceOption {
+	return func(l *layer) {
+		l.priority = priority
+	}
+}
+
+// layer 配置层
+type layer struct {
+	name     string                 // 配置源名称
+	source   Source                 // 配置源，直接加载的内容为nil
+	priority int                    // 优先级
+	format   string                 // 配置源的数据格式，写回时按该格式编码
+	checksum string                 // 当前数据的校验和，内容没有变化时不重新加载
+	data     map[string]interface{} // 配置源解码后的数据
+	watching bool                   // 是否正在监听配置源
+}
+
+// newLayer 创建配置层
+func newLayer(name string, source Source, format string, data map[string]interface{}, opts ...SourceOption) *layer {
+	l := &layer{
+		name:   name,
+		source: source,
+		format: format,
+		data:   data,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// deepMerge 将src深度合并到dst，map递归合并，其他类型直接覆盖
+func deepMerge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		if !ok {
+			dst[k] = v
+			continue
+		}
+		dstMap, ok := dst[k].(map[string]interface{})
+		if !ok {
+			dstMap = make(map[string]interface{}, len(srcMap))
+			dst[k] = dstMap
+		}
+		deepMerge(dstMap, srcMap)
+	}
+}
+
+// deepCopy 深度复制map和列表
+func deepCopy(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		dst[k] = copyValue(v)
+	}
+	return dst
+}
+
+// copyValue 深度复制值
+func copyValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return deepCopy(val)
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, item := range val {
+			res[i] = copyValue(item)
+		}
+		return res
+	default:
+		return val
+	}
+}
+
+// nest 根据路径生成嵌套的map
+func nest(paths []string, value interface{}) map[string]interface{} {
+	if len(paths) == 0 {
+		if m, ok := value.(map[string]interface{}); ok {
+			return m
+		}
+		return make(map[string]interface{})
+	}
+	res := make(map[string]interface{})
+	cur := res
+	for _, p := range paths[:len(paths)-1] {
+		next := make(map[string]interface{})
+		cur[p] = next
+		cur = next
+	}
+	cur[paths[len(paths)-1]] = value
+	return res
+}
+
+// lookup 根据路径查找值
+func lookup(data map[string]interface{}, paths []string) (interface{}, bool) {
+	var cur interface{} = data
+	for _, p := range paths {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[p]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// leaves 获取所有叶子节点的路径，空map也视为叶子节点
+func leaves(data map[string]interface{}, prefix []string) [][]string {
+	var res [][]string
+	for k, v := range data {
+		paths := append(append(make([]string, 0, len(prefix)+1), prefix...), k)
+		if m, ok := v.(map[string]interface{}); ok && len(m) > 0 {
+			res = append(res, leaves(m, paths)...)
+			continue
+		}
+		res = append(res, paths)
+	}
+	return res
+}
+
+// remove 删除路径对应的值，并清理删除后为空的上级map
+func remove(data map[string]interface{}, paths []string) {
+	if len(paths) == 0 {
+		return
+	}
+	if len(paths) == 1 {
+		delete(data, paths[0])
+		return
+	}
+	m, ok := data[paths[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	remove(m, paths[1:])
+	if len(m) == 0 {
+		delete(data, paths[0])
+	}
+}
+
+// integral 将整数值的float64和json.Number转换为int64，避免yaml、toml等格式写回时变成浮点数或字符串
+func integral(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			res[k] = integral(item)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, item := range val {
+			res[i] = integral(item)
+		}
+		return res
+	case float64:
+		if val == math.Trunc(val) && math.Abs(val) < 1<<53 {
+			return int64(val)
+		}
+		return val
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return i
+		}
+		if f, err := val.Float64(); err == nil {
+			return f
+		}
+		return val.String()
+	default:
+		return val
+	}
+}
+
+// normalize 通过json转换统一数据类型
+func normalize(data interface{}) (interface{}, error) {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
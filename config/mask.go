@@ -0,0 +1,77 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package config
+
+import "strings"
+
+// MaskValue 脱敏后显示的值
+const MaskValue = "******"
+
+// SecretKeys 需要脱敏的配置键关键字，键名(忽略大小写)包含其中之一即会被脱敏
+var SecretKeys = []string{
+	"password",
+	"passwd",
+	"secret",
+	"dsn",
+	"dns",
+	"access_key",
+	"accesskey",
+	"private_key",
+	"credential",
+}
+
+// IsSecretKey 判断配置键是否为敏感信息
+func IsSecretKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range SecretKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// MaskSecrets 复制配置并将敏感信息脱敏
+func MaskSecrets(m map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		if IsSecretKey(k) {
+			if v != nil && v != "" {
+				v = MaskValue
+			}
+			res[k] = v
+			continue
+		}
+		res[k] = maskValue(v)
+	}
+	return res
+}
+
+// maskValue 递归脱敏
+func maskValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		return MaskSecrets(val)
+	case []interface{}:
+		res := make([]interface{}, len(val))
+		for i, item := range val {
+			res[i] = maskValue(item)
+		}
+		return res
+	default:
+		return v
+	}
+}
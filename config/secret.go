@@ -0,0 +1,298 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+const (
+	// KeyEnv 默认读取密钥的环境变量，多个密钥使用逗号分隔
+	KeyEnv = "CERES_CONFIG_KEY"
+	// KeyFileEnv 默认读取密钥文件路径的环境变量
+	KeyFileEnv = "CERES_CONFIG_KEY_FILE"
+)
+
+// ErrNoKey 没有可用的密钥
+var ErrNoKey = errors.New("no config key found in keyring")
+
+// DefaultKeyring 默认的密钥环，依次从环境变量CERES_CONFIG_KEY和CERES_CONFIG_KEY_FILE指定的文件中读取密钥
+var DefaultKeyring Keyring = MultiKeyring(EnvKeyring(KeyEnv), KeyringFunc(func() ([][]byte, error) {
+	return FileKeyring(os.Getenv(KeyFileEnv)).Keys()
+}))
+
+// Keyring 密钥环，提供base64编码的AES密钥，第一个密钥用于加密，解密时依次尝试所有密钥
+type Keyring interface {
+	Keys() ([][]byte, error)
+}
+
+// KeyringFunc 方法形式的密钥环
+type KeyringFunc func() ([][]byte, error)
+
+// Keys 获取密钥
+func (f KeyringFunc) Keys() ([][]byte, error) {
+	return f()
+}
+
+// StaticKeyring 使用固定密钥的密钥环
+func StaticKeyring(keys ...[]byte) Keyring {
+	return KeyringFunc(func() ([][]byte, error) {
+		return keys, nil
+	})
+}
+
+// EnvKeyring 从环境变量读取密钥，多个密钥使用逗号分隔
+func EnvKeyring(name string) Keyring {
+	return KeyringFunc(func() ([][]byte, error) {
+		return parseKeys(strings.Split(os.Getenv(name), ","))
+	})
+}
+
+// FileKeyring 从文件读取密钥，每行一个密钥，路径为空时没有密钥
+func FileKeyring(path string) Keyring {
+	return KeyringFunc(func() ([][]byte, error) {
+		if path == "" {
+			return nil, nil
+		}
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return parseKeys(strings.Split(string(b), "\n"))
+	})
+}
+
+// MultiKeyring 合并多个密钥环，按顺序排列密钥
+func MultiKeyring(rings ...Keyring) Keyring {
+	return KeyringFunc(func() ([][]byte, error) {
+		var keys [][]byte
+		for _, ring := range rings {
+			k, err := ring.Keys()
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, k...)
+		}
+		return keys, nil
+	})
+}
+
+// parseKeys 解析base64编码的密钥，忽略空行
+func parseKeys(lines []string) ([][]byte, error) {
+	var keys [][]byte
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		key, err := base64.StdEncoding.DecodeString(line)
+		if err != nil {
+			return nil, fmt.Errorf("decode config key: %w", err)
+		}
+		switch len(key) {
+		case 16, 24, 32:
+		default:
+			return nil, fmt.Errorf("invalid config key size %d", len(key))
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// GenerateKey 生成base64编码的AES-256密钥
+func GenerateKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// IsEncrypted 判断是否为ENC(...)形式的加密值
+func IsEncrypted(s string) bool {
+	return strings.HasPrefix(s, "ENC(") && strings.HasSuffix(s, ")")
+}
+
+// WithKeyring 使用独立的密钥环解密配置，默认为DefaultKeyring
+func WithKeyring(keyring Keyring) Option {
+	return func(c *config) {
+		c.keyring = keyring
+	}
+}
+
+// SetKeyring 设置解密配置的密钥环，需要在加载配置源之前设置
+func (c *config) SetKeyring(keyring Keyring) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.keyring = keyring
+}
+
+// Keyring 获取解密配置的密钥环
+func (c *config) Keyring() Keyring {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.getKeyring()
+}
+
+// getKeyring 获取密钥环，调用方需持有锁
+func (c *config) getKeyring() Keyring {
+	if c.keyring != nil {
+		return c.keyring
+	}
+	return DefaultKeyring
+}
+
+// Encrypt 使用默认密钥环的第一个密钥加密，返回ENC(...)形式的加密值
+func Encrypt(plaintext string) (string, error) {
+	return EncryptWith(DefaultKeyring, plaintext)
+}
+
+// EncryptWith 使用密钥环的第一个密钥加密
+func EncryptWith(keyring Keyring, plaintext string) (string, error) {
+	keys, err := keyring.Keys()
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", ErrNoKey
+	}
+	gcm, err := newGCM(keys[0])
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return "ENC(" + base64.StdEncoding.EncodeToString(sealed) + ")", nil
+}
+
+// Decrypt 使用默认密钥环解密ENC(...)形式的加密值
+func Decrypt(value string) (string, error) {
+	return DecryptWith(DefaultKeyring, value)
+}
+
+// DecryptWith 使用密钥环解密，依次尝试所有密钥
+func DecryptWith(keyring Keyring, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", errors.New("value is not in ENC(...) form")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(value[4 : len(value)-1])
+	if err != nil {
+		return "", err
+	}
+	keys, err := keyring.Keys()
+	if err != nil {
+		return "", err
+	}
+	if len(keys) == 0 {
+		return "", ErrNoKey
+	}
+	for _, key := range keys {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return "", err
+		}
+		if len(sealed) < gcm.NonceSize() {
+			return "", errors.New("encrypted value too short")
+		}
+		nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+		if plaintext, err := gcm.Open(nil, nonce, ciphertext, nil); err == nil {
+			return string(plaintext), nil
+		}
+	}
+	return "", errors.New("decrypt value: no key matched")
+}
+
+// newGCM 创建AES-GCM
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// decryptValues 解密配置中所有ENC(...)形式的值，返回解密过的配置路径，列表中有加密值时记录列表路径
+func decryptValues(keyring Keyring, data map[string]interface{}) (map[string]bool, error) {
+	secrets := make(map[string]bool)
+	for k, v := range data {
+		res, ok, err := decryptValue(keyring, v, k, secrets)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			data[k] = res
+			secrets[k] = true
+		}
+	}
+	return secrets, nil
+}
+
+// decryptValue 递归解密，ok表示该值本身包含加密值
+func decryptValue(keyring Keyring, v interface{}, path string, secrets map[string]bool) (interface{}, bool, error) {
+	switch val := v.(type) {
+	case string:
+		if !IsEncrypted(val) {
+			return val, false, nil
+		}
+		plaintext, err := DecryptWith(keyring, val)
+		if err != nil {
+			return nil, false, fmt.Errorf("decrypt %s: %w", path, err)
+		}
+		return plaintext, true, nil
+	case map[string]interface{}:
+		for k, item := range val {
+			sub := path + "." + k
+			res, ok, err := decryptValue(keyring, item, sub, secrets)
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				val[k] = res
+				secrets[sub] = true
+			}
+		}
+		return val, false, nil
+	case []interface{}:
+		found := false
+		for i, item := range val {
+			// 列表中的值没有独立路径，包含加密值时整个列表视为敏感信息
+			nested := make(map[string]bool)
+			res, ok, err := decryptValue(keyring, item, path, nested)
+			if err != nil {
+				return nil, false, err
+			}
+			val[i] = res
+			if ok || len(nested) > 0 {
+				found = true
+			}
+		}
+		return val, found, nil
+	default:
+		return v, false, nil
+	}
+}
@@ -0,0 +1,112 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	oldKey, _ := GenerateKey()
+	newKey, _ := GenerateKey()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "key")
+	_ = ioutil.WriteFile(path, []byte(newKey+"\n"+oldKey+"\n"), 0600)
+
+	old, _ := base64.StdEncoding.DecodeString(oldKey)
+	enc, err := EncryptWith(StaticKeyring(old), "s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsEncrypted(enc) {
+		t.Fatalf("%s is not encrypted", enc)
+	}
+	// 轮换后的密钥环仍能解密旧密钥加密的值
+	plain, err := DecryptWith(FileKeyring(path), enc)
+	if err != nil || plain != "s3cret" {
+		t.Fatalf("DecryptWith() = %s, %v", plain, err)
+	}
+	if _, err := DecryptWith(StaticKeyring(), enc); err != ErrNoKey {
+		t.Fatalf("DecryptWith() err = %v, want %v", err, ErrNoKey)
+	}
+	if _, err := parseKeys([]string{base64.StdEncoding.EncodeToString([]byte("short"))}); err == nil {
+		t.Fatal("invalid key size should fail")
+	}
+}
+
+func TestConfigDecrypt(t *testing.T) {
+	key, _ := GenerateKey()
+	t.Setenv(KeyEnv, key)
+	password, _ := Encrypt("s3cret")
+	token, _ := Encrypt("t0ken")
+	c := NewConfig()
+	err := c.Load([]byte(`{"redis":{"password":"`+password+`","auth":"`+token+`","addrs":["127.0.0.1"]},"hosts":["`+token+`"]}`), "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := c.Get("redis.password").String(""); v != "s3cret" {
+		t.Fatalf("redis.password = %s", v)
+	}
+	var redis struct {
+		Auth string `json:"auth"`
+	}
+	if err := c.Get("redis").Scan(&redis); err != nil || redis.Auth != "t0ken" {
+		t.Fatalf("Scan() = %+v, %v", redis, err)
+	}
+	masked := c.Masked()
+	out := strings.Join([]string{
+		masked["redis"].(map[string]interface{})["password"].(string),
+		masked["redis"].(map[string]interface{})["auth"].(string),
+		masked["hosts"].(string),
+	}, ",")
+	if out != "******,******,******" {
+		t.Fatalf("masked = %v", masked)
+	}
+
+	t.Setenv(KeyEnv, "")
+	if err := NewConfig().Load([]byte(`{"password":"`+password+`"}`), "json"); err == nil {
+		t.Fatal("load without key should fail")
+	}
+}
+
+func TestConfigKeyring(t *testing.T) {
+	key, _ := GenerateKey()
+	raw, _ := base64.StdEncoding.DecodeString(key)
+	t.Setenv(KeyEnv, "")
+	enc, _ := EncryptWith(StaticKeyring(raw), "s3cret")
+	content := []byte(`{"db":{"password":"` + enc + `"}}`)
+
+	// 每个配置管理器使用自己的密钥环，不依赖全局密钥环
+	c := NewConfig(WithKeyring(StaticKeyring(raw)))
+	if err := c.Load(content, "json"); err != nil {
+		t.Fatal(err)
+	}
+	if password := c.Get("db.password").String(""); password != "s3cret" {
+		t.Fatalf("db.password = %s, want s3cret", password)
+	}
+	if err := NewConfig().Load(content, "json"); err == nil {
+		t.Fatal("config without key should fail to decrypt")
+	}
+	other := NewConfig()
+	other.SetKeyring(StaticKeyring(raw))
+	if err := other.Load(content, "json"); err != nil {
+		t.Fatalf("load with keyring set err = %v", err)
+	}
+}
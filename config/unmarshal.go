@@ -16,6 +16,7 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"encoding/xml"
 	"github.com/BurntSushi/toml"
@@ -30,6 +31,21 @@ var (
 		"yml":  yaml.Unmarshal,
 		"toml": toml.Unmarshal,
 	}
+	Marshals = map[string]Marshal{
+		"json": func(v interface{}) ([]byte, error) {
+			return json.MarshalIndent(v, "", "  ")
+		},
+		"yaml": yaml.Marshal,
+		"yml":  yaml.Marshal,
+		"toml": func(v interface{}) ([]byte, error) {
+			b := bytes.NewBuffer(nil)
+			if err := toml.NewEncoder(b).Encode(v); err != nil {
+				return nil, err
+			}
+			return b.Bytes(), nil
+		},
+	}
 )
 
 type Unmarshal func(data []byte, v interface{}) error
+type Marshal func(v interface{}) ([]byte, error)
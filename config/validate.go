@@ -0,0 +1,104 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidateFunc 配置校验方法，参数为候选配置中该路径下的值
+type ValidateFunc func(v Value) error
+
+// RejectFunc 配置重新加载被拒绝时的回调，参数为配置源名称和拒绝原因
+type RejectFunc func(source string, err error)
+
+// validator 配置校验
+type validator struct {
+	path string
+	fn   ValidateFunc
+}
+
+// AddValidator 添加配置路径的校验方法，路径为空时校验全部配置，
+// 每次配置变化都会先校验候选配置，校验失败时保留原配置
+func (c *config) AddValidator(path string, fn ValidateFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.validators = append(c.validators, validator{path: path, fn: fn})
+}
+
+// AddSchema 添加配置路径的JSON Schema校验，路径下没有配置时不校验
+func (c *config) AddSchema(path string, schema []byte) error {
+	s, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(schema))
+	if err != nil {
+		return err
+	}
+	c.AddValidator(path, func(v Value) error {
+		if v.IsEmpty() {
+			return nil
+		}
+		var data interface{}
+		if err := v.Scan(&data); err != nil {
+			return err
+		}
+		res, err := s.Validate(gojsonschema.NewGoLoader(data))
+		if err != nil {
+			return err
+		}
+		if res.Valid() {
+			return nil
+		}
+		errs := make([]string, 0, len(res.Errors()))
+		for _, e := range res.Errors() {
+			errs = append(errs, e.String())
+		}
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	})
+	return nil
+}
+
+// OnReject 添加配置重新加载被拒绝时的回调
+func (c *config) OnReject(fn RejectFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onRejects = append(c.onRejects, fn)
+}
+
+// validate 校验候选配置，调用方需持有锁
+func (c *config) validate(store *JSONValues) error {
+	for _, v := range c.validators {
+		if err := v.fn(store.Get(v.path)); err != nil {
+			if v.path == "" {
+				return fmt.Errorf("validate config: %w", err)
+			}
+			return fmt.Errorf("validate config %s: %w", v.path, err)
+		}
+	}
+	return nil
+}
+
+// reject 通知配置重新加载被拒绝
+func (c *config) reject(source string, err error) {
+	c.mu.RLock()
+	rejects := make([]RejectFunc, len(c.onRejects))
+	copy(rejects, c.onRejects)
+	c.mu.RUnlock()
+	for _, fn := range rejects {
+		fn(source, err)
+	}
+}
@@ -0,0 +1,80 @@
+//   Copyright 2021 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestConfigValidate(t *testing.T) {
+	c := NewConfig()
+	remote := newMemorySource("remote", `{"redis":{"addrs":["127.0.0.1:6379"]},"server":{"port":80}}`)
+	_ = c.LoadSource(remote)
+	c.AddValidator("redis.addrs", func(v Value) error {
+		if len(v.StringSlice(nil)) == 0 {
+			return errors.New("addrs is empty")
+		}
+		return nil
+	})
+	err := c.AddSchema("server", []byte(`{
+		"type": "object",
+		"properties": {"port": {"type": "integer", "minimum": 1, "maximum": 65535}},
+		"required": ["port"]
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AddSchema("bad", []byte(`{"type": 1}`)); err == nil {
+		t.Fatal("invalid schema should fail")
+	}
+	rejected := make(chan error, 1)
+	c.OnReject(func(source string, err error) {
+		if source != "remote" {
+			t.Errorf("source = %s, want remote", source)
+		}
+		rejected <- err
+	})
+	c.Watch()
+	defer c.UnWatch()
+
+	for _, data := range []string{
+		`{"redis":{"addrs":[]},"server":{"port":80}}`,
+		`{"redis":{"addrs":["127.0.0.1:6379"]},"server":{"port":-1}}`,
+	} {
+		remote.data = data
+		remote.changed <- struct{}{}
+		select {
+		case err := <-rejected:
+			if !strings.HasPrefix(err.Error(), "validate config") {
+				t.Fatalf("err = %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("reload not rejected")
+		}
+		if port := c.Get("server.port").Int(0); port != 80 {
+			t.Fatalf("server.port = %d, want 80", port)
+		}
+	}
+	if err := c.Set("server.port", 70000); err == nil {
+		t.Fatal("invalid set should fail")
+	}
+	if err := c.Set("server.port", 8080); err != nil {
+		t.Fatal(err)
+	}
+}
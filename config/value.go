@@ -16,7 +16,6 @@
 package config
 
 import (
-	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
@@ -99,7 +98,7 @@ func (j *JSONValues) Scan(v interface{}) error {
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(b, v)
+	return scan(b, v)
 }
 
 func (j *JSONValues) String() string {
@@ -220,7 +219,7 @@ func (j *JSONValue) Scan(v interface{}) error {
 	if err != nil {
 		return err
 	}
-	return json.Unmarshal(b, v)
+	return scan(b, v)
 }
 
 func (j *JSONValue) Bytes() []byte {
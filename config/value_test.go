@@ -21,6 +21,7 @@ import (
 	"github.com/BurntSushi/toml"
 	"log"
 	"testing"
+	"time"
 )
 
 func TestJSONValue_Exists(t *testing.T) {
@@ -53,3 +54,27 @@ enabled = true`, &data)
 	}
 	fmt.Println(decode)
 }
+
+func TestScanDuration(t *testing.T) {
+	type inner struct {
+		Interval time.Duration `json:"interval"`
+	}
+	type conf struct {
+		Timeout  time.Duration            `json:"timeout"`
+		Retry    time.Duration            `json:"retry"`
+		Inner    *inner                   `json:"inner"`
+		Backoffs []time.Duration          `json:"backoffs"`
+		Named    map[string]time.Duration `json:"named"`
+	}
+	values := NewJSONValues([]byte(`{"timeout":"1m30s","retry":1000000000,"inner":{"interval":"2s"},"backoffs":["1s",2000000000],"named":{"a":"5ms"}}`))
+	var c conf
+	if err := values.Scan(&c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Timeout != 90*time.Second || c.Retry != time.Second || c.Inner.Interval != 2*time.Second {
+		t.Fatalf("scanned = %+v", c)
+	}
+	if c.Backoffs[0] != time.Second || c.Backoffs[1] != 2*time.Second || c.Named["a"] != 5*time.Millisecond {
+		t.Fatalf("scanned = %+v", c)
+	}
+}
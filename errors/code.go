@@ -24,6 +24,10 @@ const (
 	CodeGetServiceErrorNotFound        = 4004
 	CodeWatchServiceErrorNoServiceName = 4005
 
+	// CodeAddWorkerErrorDuplicate 添加后台任务错误（名称重复）
+	CodeAddWorkerErrorDuplicate = 4100
+	CodeAddWorkerErrorStopping  = 4101
+
 	// CodeAddScheduleToMaximum 添加定时任务错误（添加数量超过定义数量）
 	CodeAddScheduleToMaximum = 5000
 
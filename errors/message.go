@@ -23,6 +23,10 @@ const (
 	MsgWatcherServiceErrorPassFor     = "could not get next,pass for"
 	MsgGetServiceErrorNotFound        = "service not found"
 	MsgWatchServiceErrorNoServiceName = "missing service parameter"
+
+	// MsgAddWorkerErrorDuplicate 添加后台任务错误（名称重复）
+	MsgAddWorkerErrorDuplicate = "worker already exists"
+	MsgAddWorkerErrorStopping  = "engine is stopping, could not add worker"
 	// MsgAddScheduleToMaximum schedule 统一错误信息
 	MsgAddScheduleToMaximum = "Maximum number of tasks added exceeded"
 
@@ -17,6 +17,7 @@ package errors
 
 const (
 	ModApp          = "app"
+	ModConfig       = "config"
 	ModLogger       = "logger"
 	ModClientEtcd   = "client.etcd"
 	ModRegistryEtcd = "registry.etcd"
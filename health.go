@@ -0,0 +1,100 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/health"
+	"github.com/go-ceres/go-ceres/logger"
+	"github.com/go-ceres/go-ceres/server"
+	"time"
+)
+
+// initHealth 初始化健康检查
+func (eng *Engine) initHealth() error {
+	eng.healthEvery = eng.conf.Get("ceres.health.interval").Duration(10 * time.Second)
+	eng.unwatchHealth = eng.health.OnChange(eng.onHealthChange)
+	return nil
+}
+
+// stopHealth 取消健康状态变化监听，避免停止后仍被健康检查组件回调
+func (eng *Engine) stopHealth() {
+	if eng.unwatchHealth != nil {
+		eng.unwatchHealth()
+	}
+}
+
+// drainServers 通知服务开始停止，让健康检查先返回不可用
+func (eng *Engine) drainServers() {
+	for _, s := range eng.allServers() {
+		if d, ok := s.(server.Drainer); ok {
+			d.Drain()
+		}
+	}
+}
+
+// Health 获取健康检查组件
+func (eng *Engine) Health() *health.Health {
+	return eng.health
+}
+
+// watchHealth 定时刷新健康状态，停止时退出
+func (eng *Engine) watchHealth() error {
+	eng.health.Watch(eng.ctx, eng.healthEvery)
+	return nil
+}
+
+// isHealthy 最近一次就绪检查是否通过，未检查过视为通过
+func (eng *Engine) isHealthy() bool {
+	report := eng.health.Last()
+	return report == nil || report.IsUp()
+}
+
+// onHealthChange 就绪状态变化时，不健康则从注册中心注销，恢复后重新注册
+func (eng *Engine) onHealthChange(report *health.Report) {
+	if eng.registry == nil || eng.isStopping() {
+		return
+	}
+	if report.IsUp() {
+		eng.logger.Infod("health recovered, register services", logger.FieldMod(errors.ModApp))
+		eng.registerServers()
+		return
+	}
+	eng.logger.Warnd("health check failed, deregister services", logger.FieldMod(errors.ModApp), logger.FieldValue(report))
+	eng.deregisterServers()
+}
+
+// liveness 存活检查
+func (eng *Engine) liveness(ctx context.Context) (interface{}, error) {
+	report := eng.health.Live(ctx)
+	if !report.IsUp() {
+		return report, errHealthDown
+	}
+	return report, nil
+}
+
+// readiness 就绪检查，引擎未启动完成或健康检查未通过时均为未就绪
+func (eng *Engine) readiness(ctx context.Context) (interface{}, error) {
+	if !eng.isReady() {
+		return nil, errNotReady
+	}
+	report := eng.health.Ready(ctx)
+	if !report.IsUp() {
+		return report, errHealthDown
+	}
+	return report, nil
+}
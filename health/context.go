@@ -0,0 +1,36 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package health
+
+import "context"
+
+// contextKey 上下文中保存健康检查组件的key
+type contextKey struct{}
+
+// NewContext 返回携带健康检查组件的上下文
+func NewContext(ctx context.Context, h *Health) context.Context {
+	return context.WithValue(ctx, contextKey{}, h)
+}
+
+// FromContext 从上下文中获取健康检查组件，不存在时返回DefaultHealth
+func FromContext(ctx context.Context) *Health {
+	if ctx != nil {
+		if h, ok := ctx.Value(contextKey{}).(*Health); ok && h != nil {
+			return h
+		}
+	}
+	return DefaultHealth
+}
@@ -0,0 +1,54 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package health
+
+import (
+	"context"
+	"time"
+)
+
+// DefaultHealth 默认的健康检查管理器
+var DefaultHealth = New()
+
+// Register 注册检查项
+func Register(name string, checker Checker, opts ...Option) error {
+	return DefaultHealth.Register(name, checker, opts...)
+}
+
+// Deregister 注销检查项
+func Deregister(name string) {
+	DefaultHealth.Deregister(name)
+}
+
+// Live 运行存活检查
+func Live(ctx context.Context) *Report {
+	return DefaultHealth.Live(ctx)
+}
+
+// Ready 运行就绪检查
+func Ready(ctx context.Context) *Report {
+	return DefaultHealth.Ready(ctx)
+}
+
+// OnChange 就绪状态变化时回调，返回取消回调的方法
+func OnChange(fn func(report *Report)) func() {
+	return DefaultHealth.OnChange(fn)
+}
+
+// Watch 按时间间隔定时刷新就绪状态
+func Watch(ctx context.Context, interval time.Duration) {
+	DefaultHealth.Watch(ctx, interval)
+}
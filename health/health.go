@@ -0,0 +1,346 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status 健康状态
+type Status string
+
+const (
+	StatusUp   Status = "UP"   // 健康
+	StatusDown Status = "DOWN" // 不健康
+)
+
+// Kind 检查类型
+type Kind int
+
+const (
+	KindReadiness Kind = 1 << iota // 就绪检查，不通过时不接收流量
+	KindLiveness                   // 存活检查，不通过时表示进程需要重启
+)
+
+// DefaultTimeout 单个检查默认超时时间
+const DefaultTimeout = 3 * time.Second
+
+// Checker 健康检查接口
+type Checker interface {
+	// Check 检查，返回错误表示不健康
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc 健康检查方法
+type CheckerFunc func(ctx context.Context) error
+
+// Check 实现Checker接口
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// Result 单个检查结果
+type Result struct {
+	Name     string `json:"name"`            // 检查名称
+	Status   Status `json:"status"`          // 状态
+	Error    string `json:"error,omitempty"` // 错误信息
+	Duration string `json:"duration"`        // 耗时
+}
+
+// Report 检查报告
+type Report struct {
+	Status Status   `json:"status"` // 整体状态
+	Checks []Result `json:"checks"` // 各个检查的结果
+}
+
+// IsUp 是否健康
+func (r *Report) IsUp() bool {
+	return r.Status == StatusUp
+}
+
+// check 已注册的检查项
+type check struct {
+	name    string
+	kind    Kind
+	timeout time.Duration
+	checker Checker
+}
+
+// Option 检查项选项
+type Option func(c *check)
+
+// WithKind 设置检查类型，默认为就绪检查
+func WithKind(kind Kind) Option {
+	return func(c *check) {
+		c.kind = kind
+	}
+}
+
+// WithTimeout 设置检查超时时间
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *check) {
+		c.timeout = timeout
+	}
+}
+
+// Health 健康检查管理器
+type Health struct {
+	mu        sync.RWMutex
+	checks    []*check
+	ready     *Report
+	listeners map[int]func(report *Report)
+	subs      map[int]chan struct{}
+	subId     int
+}
+
+// New 创建健康检查管理器
+func New() *Health {
+	return &Health{
+		checks:    make([]*check, 0),
+		listeners: make(map[int]func(report *Report)),
+		subs:      make(map[int]chan struct{}),
+	}
+}
+
+// Register 注册检查项
+func (h *Health) Register(name string, checker Checker, opts ...Option) error {
+	c := &check{
+		name:    name,
+		kind:    KindReadiness,
+		timeout: DefaultTimeout,
+		checker: checker,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, item := range h.checks {
+		if item.name == name {
+			return fmt.Errorf("health checker %s already registered", name)
+		}
+	}
+	h.checks = append(h.checks, c)
+	return nil
+}
+
+// Deregister 注销检查项
+func (h *Health) Deregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i, item := range h.checks {
+		if item.name == name {
+			h.checks = append(h.checks[:i], h.checks[i+1:]...)
+			return
+		}
+	}
+}
+
+// Names 获取所有检查项名称
+func (h *Health) Names() []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	names := make([]string, 0, len(h.checks))
+	for _, c := range h.checks {
+		names = append(names, c.name)
+	}
+	return names
+}
+
+// Live 运行存活检查
+func (h *Health) Live(ctx context.Context) *Report {
+	return h.run(ctx, h.filter(func(c *check) bool {
+		return c.kind&KindLiveness != 0
+	}))
+}
+
+// Ready 运行就绪检查
+func (h *Health) Ready(ctx context.Context) *Report {
+	return h.run(ctx, h.filter(func(c *check) bool {
+		return c.kind&KindReadiness != 0
+	}))
+}
+
+// Check 运行指定的检查项
+func (h *Health) Check(ctx context.Context, name string) (*Report, bool) {
+	checks := h.filter(func(c *check) bool {
+		return c.name == name
+	})
+	if len(checks) == 0 {
+		return nil, false
+	}
+	return h.run(ctx, checks), true
+}
+
+// Refresh 运行就绪检查并缓存结果，状态变化时通知监听者
+func (h *Health) Refresh(ctx context.Context) *Report {
+	report := h.Ready(ctx)
+	h.mu.Lock()
+	changed := h.ready == nil || h.ready.Status != report.Status
+	h.ready = report
+	listeners := make([]func(report *Report), 0, len(h.listeners))
+	for id := 1; id <= h.subId; id++ {
+		if fn, ok := h.listeners[id]; ok {
+			listeners = append(listeners, fn)
+		}
+	}
+	if changed {
+		h.notify()
+	}
+	h.mu.Unlock()
+	if changed {
+		for _, fn := range listeners {
+			fn(report)
+		}
+	}
+	return report
+}
+
+// Last 获取最近一次Refresh的就绪结果，从未检查过时返回nil
+func (h *Health) Last() *Report {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.ready
+}
+
+// OnChange 就绪状态变化时回调，返回取消回调的方法
+func (h *Health) OnChange(fn func(report *Report)) func() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subId++
+	id := h.subId
+	h.listeners[id] = fn
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.listeners, id)
+	}
+}
+
+// Subscribe 订阅就绪状态变化，返回通知通道及取消订阅方法
+func (h *Health) Subscribe() (<-chan struct{}, func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.subId++
+	id := h.subId
+	ch := make(chan struct{}, 1)
+	h.subs[id] = ch
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs, id)
+	}
+}
+
+// Watch 按时间间隔定时刷新就绪状态，ctx结束时退出
+func (h *Health) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	h.Refresh(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.Refresh(ctx)
+		}
+	}
+}
+
+// notify 通知订阅者状态变化，需要持有锁
+func (h *Health) notify() {
+	for _, ch := range h.subs {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// filter 过滤检查项
+func (h *Health) filter(fn func(c *check) bool) []*check {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	res := make([]*check, 0, len(h.checks))
+	for _, c := range h.checks {
+		if fn(c) {
+			res = append(res, c)
+		}
+	}
+	return res
+}
+
+// run 并发运行检查项并汇总结果
+func (h *Health) run(ctx context.Context, checks []*check) *Report {
+	report := &Report{
+		Status: StatusUp,
+		Checks: make([]Result, len(checks)),
+	}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			report.Checks[i] = c.run(ctx)
+		}(i, c)
+	}
+	wg.Wait()
+	for _, res := range report.Checks {
+		if res.Status != StatusUp {
+			report.Status = StatusDown
+			break
+		}
+	}
+	return report
+}
+
+// run 运行单个检查项
+func (c *check) run(ctx context.Context) Result {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("panic: %v", rec)
+			}
+		}()
+		done <- c.checker.Check(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	res := Result{
+		Name:     c.name,
+		Status:   StatusUp,
+		Duration: time.Since(start).String(),
+	}
+	if err != nil {
+		res.Status = StatusDown
+		res.Error = err.Error()
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestHealth_Ready(t *testing.T) {
+	h := New()
+	failed := errors.New("connection refused")
+	var down bool
+	_ = h.Register("db", CheckerFunc(func(ctx context.Context) error {
+		if down {
+			return failed
+		}
+		return nil
+	}))
+	_ = h.Register("process", CheckerFunc(func(ctx context.Context) error {
+		return nil
+	}), WithKind(KindLiveness))
+
+	if err := h.Register("db", CheckerFunc(func(ctx context.Context) error { return nil })); err == nil {
+		t.Fatal("register duplicate checker should fail")
+	}
+
+	var changes []Status
+	h.OnChange(func(report *Report) {
+		changes = append(changes, report.Status)
+	})
+
+	if report := h.Refresh(context.Background()); !report.IsUp() || len(report.Checks) != 1 {
+		t.Fatalf("ready report = %+v, want UP with 1 check", report)
+	}
+	down = true
+	if report := h.Refresh(context.Background()); report.IsUp() || report.Checks[0].Error != failed.Error() {
+		t.Fatalf("ready report = %+v, want DOWN", report)
+	}
+	h.Refresh(context.Background())
+	if len(changes) != 2 || changes[1] != StatusDown {
+		t.Fatalf("changes = %v, want [UP DOWN]", changes)
+	}
+	if report := h.Live(context.Background()); !report.IsUp() {
+		t.Fatalf("live report = %+v, want UP", report)
+	}
+}
+
+func TestHealth_Timeout(t *testing.T) {
+	h := New()
+	_ = h.Register("slow", CheckerFunc(func(ctx context.Context) error {
+		<-ctx.Done()
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	}), WithTimeout(10*time.Millisecond))
+	if report := h.Ready(context.Background()); report.IsUp() {
+		t.Fatalf("ready report = %+v, want DOWN on timeout", report)
+	}
+}
+
+func TestHealth_OnChangeCancel(t *testing.T) {
+	h := New()
+	var down bool
+	_ = h.Register("db", CheckerFunc(func(ctx context.Context) error {
+		if down {
+			return errors.New("down")
+		}
+		return nil
+	}))
+	var calls int
+	cancel := h.OnChange(func(report *Report) {
+		calls++
+	})
+	h.Refresh(context.Background())
+	cancel()
+	down = true
+	h.Refresh(context.Background())
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1 after cancel", calls)
+	}
+}
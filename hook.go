@@ -0,0 +1,192 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/logger"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	stageBeforeStart = "before start"
+	stageAfterStart  = "after start"
+	stageBeforeStop  = "before stop"
+	stageAfterStop   = "after stop"
+)
+
+// HookFunc 生命周期钩子方法
+type HookFunc func(ctx context.Context) error
+
+// Hook 生命周期钩子
+type Hook struct {
+	Name     string        // 钩子名称
+	Priority int           // 优先级，数值越小越先执行，相同优先级按注册顺序执行
+	Timeout  time.Duration // 单个钩子的超时时间，0表示不超时
+	Fn       HookFunc      // 钩子方法
+}
+
+// HookOption 钩子选项
+type HookOption func(h *Hook)
+
+// HookPriority 设置钩子优先级
+func HookPriority(priority int) HookOption {
+	return func(h *Hook) {
+		h.Priority = priority
+	}
+}
+
+// HookTimeout 设置钩子超时时间
+func HookTimeout(timeout time.Duration) HookOption {
+	return func(h *Hook) {
+		h.Timeout = timeout
+	}
+}
+
+// HookErrors 多个钩子的错误集合
+type HookErrors []error
+
+// Error 实现error接口
+func (e HookErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// newHook 创建钩子
+func newHook(name string, fn HookFunc, opts ...HookOption) *Hook {
+	h := &Hook{
+		Name: name,
+		Fn:   fn,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// run 运行钩子，超时后直接返回超时错误
+func (h *Hook) run(ctx context.Context) error {
+	if h.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.Timeout)
+		defer cancel()
+	}
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				done <- fmt.Errorf("panic: %v", rec)
+			}
+		}()
+		done <- h.Fn(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// BeforeStart 添加启动前钩子，返回错误时终止启动
+func (eng *Engine) BeforeStart(name string, fn HookFunc, opts ...HookOption) *Engine {
+	return eng.addHook(&eng.beforeStarts, newHook(name, fn, opts...))
+}
+
+// AfterStart 添加启动后钩子，返回错误时终止启动
+func (eng *Engine) AfterStart(name string, fn HookFunc, opts ...HookOption) *Engine {
+	return eng.addHook(&eng.afterStarts, newHook(name, fn, opts...))
+}
+
+// BeforeStop 添加停止前钩子，返回错误时仅记录日志
+func (eng *Engine) BeforeStop(name string, fn HookFunc, opts ...HookOption) *Engine {
+	return eng.addHook(&eng.beforeStops, newHook(name, fn, opts...))
+}
+
+// AfterStop 添加停止后钩子，返回错误时仅记录日志
+func (eng *Engine) AfterStop(name string, fn HookFunc, opts ...HookOption) *Engine {
+	return eng.addHook(&eng.afterStops, newHook(name, fn, opts...))
+}
+
+// addHook 按优先级添加钩子
+func (eng *Engine) addHook(hooks *[]*Hook, h *Hook) *Engine {
+	eng.initialize()
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	*hooks = append(*hooks, h)
+	sort.SliceStable(*hooks, func(i, j int) bool {
+		return (*hooks)[i].Priority < (*hooks)[j].Priority
+	})
+	return eng
+}
+
+// runStartHooks 运行启动钩子，遇到错误立即返回
+func (eng *Engine) runStartHooks(ctx context.Context, stage string, hooks *[]*Hook) error {
+	for _, h := range eng.copyHooks(hooks) {
+		if err := eng.runHook(ctx, stage, h); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runStopHooks 运行停止钩子，出错后继续执行，返回所有错误
+func (eng *Engine) runStopHooks(ctx context.Context, stage string, hooks *[]*Hook) error {
+	var errs HookErrors
+	for _, h := range eng.copyHooks(hooks) {
+		if err := eng.runHook(ctx, stage, h); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// runHook 运行单个钩子并记录耗时
+func (eng *Engine) runHook(ctx context.Context, stage string, h *Hook) error {
+	start := time.Now()
+	err := h.run(ctx)
+	fields := []logger.Field{
+		logger.FieldMod(errors.ModApp),
+		logger.FieldString("stage", stage),
+		logger.FieldString("name", h.Name),
+		logger.FieldString("cost", time.Since(start).String()),
+	}
+	if err != nil {
+		eng.logger.Errord("run hook", append(fields, logger.FieldErr(err))...)
+		return fmt.Errorf("%s hook %s: %w", stage, h.Name, err)
+	}
+	eng.logger.Infod("run hook", fields...)
+	return nil
+}
+
+// copyHooks 复制钩子列表，避免运行时持有锁
+func (eng *Engine) copyHooks(hooks *[]*Hook) []*Hook {
+	eng.rw.RLock()
+	defer eng.rw.RUnlock()
+	res := make([]*Hook, len(*hooks))
+	copy(res, *hooks)
+	return res
+}
@@ -0,0 +1,127 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHook_Priority(t *testing.T) {
+	eng := NewEngine()
+	var order []string
+	record := func(name string) HookFunc {
+		return func(ctx context.Context) error {
+			order = append(order, name)
+			return nil
+		}
+	}
+	eng.BeforeStart("c", record("c"), HookPriority(10))
+	eng.BeforeStart("a", record("a"), HookPriority(-1))
+	eng.BeforeStart("b1", record("b1"))
+	eng.BeforeStart("b2", record("b2"))
+	if err := eng.runStartHooks(context.Background(), stageBeforeStart, &eng.beforeStarts); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(order, ","); got != "a,b1,b2,c" {
+		t.Fatalf("order = %s, want a,b1,b2,c", got)
+	}
+}
+
+func TestHook_Timeout(t *testing.T) {
+	eng := NewEngine()
+	eng.BeforeStart("slow", func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}, HookTimeout(20*time.Millisecond))
+	start := time.Now()
+	err := eng.runStartHooks(context.Background(), stageBeforeStart, &eng.beforeStarts)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Fatalf("hook took %v, should return on timeout", elapsed)
+	}
+}
+
+func TestHook_StartStopsAtFirstError(t *testing.T) {
+	eng := NewEngine()
+	failed := errors.New("failed")
+	var ran []string
+	eng.AfterStart("first", func(ctx context.Context) error {
+		ran = append(ran, "first")
+		return failed
+	})
+	eng.AfterStart("second", func(ctx context.Context) error {
+		ran = append(ran, "second")
+		return nil
+	})
+	err := eng.runStartHooks(context.Background(), stageAfterStart, &eng.afterStarts)
+	if !errors.Is(err, failed) || !strings.Contains(err.Error(), "after start hook first") {
+		t.Fatalf("err = %v, want first hook error", err)
+	}
+	if len(ran) != 1 {
+		t.Fatalf("ran = %v, want only first hook", ran)
+	}
+}
+
+func TestHook_StopCollectsErrors(t *testing.T) {
+	eng := NewEngine()
+	var ran int
+	for _, name := range []string{"a", "b", "c"} {
+		name := name
+		eng.BeforeStop(name, func(ctx context.Context) error {
+			ran++
+			if name == "b" {
+				return nil
+			}
+			return errors.New(name + " failed")
+		})
+	}
+	err := eng.runStopHooks(context.Background(), stageBeforeStop, &eng.beforeStops)
+	var errs HookErrors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Fatalf("err = %v, want 2 hook errors", err)
+	}
+	if ran != 3 {
+		t.Fatalf("ran = %d, want all 3 hooks", ran)
+	}
+	if got := err.Error(); got != "before stop hook a: a failed; before stop hook c: c failed" {
+		t.Fatalf("err = %s", got)
+	}
+}
+
+func TestHook_Panic(t *testing.T) {
+	eng := NewEngine()
+	var after bool
+	eng.AfterStop("panic", func(ctx context.Context) error {
+		panic("boom")
+	})
+	eng.AfterStop("after", func(ctx context.Context) error {
+		after = true
+		return nil
+	})
+	err := eng.runStopHooks(context.Background(), stageAfterStop, &eng.afterStops)
+	if err == nil || !strings.Contains(err.Error(), "panic: boom") {
+		t.Fatalf("err = %v, want recovered panic", err)
+	}
+	if !after {
+		t.Fatal("hooks after a panic should still run")
+	}
+}
@@ -0,0 +1,140 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/go-ceres/go-ceres/logger"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// errLeadershipLost 失去leader身份
+var errLeadershipLost = errors.New("leadership lost")
+
+// leaderWorker 选主后台任务名称
+const leaderWorker = "leader-election"
+
+// Elector 选主，如etcd.Election
+type Elector interface {
+	// Campaign 参与竞选，阻塞直到成为leader或ctx结束
+	Campaign(ctx context.Context, val string) error
+	// Resign 放弃leader身份
+	Resign(ctx context.Context) error
+	// Done 当前任期结束时关闭
+	Done() <-chan struct{}
+}
+
+// LeaderFunc 成为leader后运行的方法，ctx在失去leader身份时取消
+type LeaderFunc func(ctx context.Context)
+
+// SetElector 设置选主
+func (eng *Engine) SetElector(elector Elector) {
+	eng.initialize()
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	eng.elector = elector
+}
+
+// OnLeader 添加成为leader后运行的方法，需要先设置选主
+func (eng *Engine) OnLeader(fn LeaderFunc) *Engine {
+	eng.initialize()
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	eng.leaders = append(eng.leaders, fn)
+	return eng
+}
+
+// IsLeader 当前实例是否为leader
+func (eng *Engine) IsLeader() bool {
+	eng.rw.RLock()
+	defer eng.rw.RUnlock()
+	return eng.leader
+}
+
+// startElection 设置了选主和leader方法时，以后台任务的方式参与选主
+func (eng *Engine) startElection() error {
+	eng.rw.RLock()
+	elector, count := eng.elector, len(eng.leaders)
+	eng.rw.RUnlock()
+	if elector == nil || count == 0 {
+		return nil
+	}
+	return eng.Worker(leaderWorker, eng.campaign(elector), WorkerRestart(RestartAlways), WorkerBackoff(time.Second, 30*time.Second))
+}
+
+// campaign 竞选leader，成为leader后运行所有leader方法直到失去leader身份
+func (eng *Engine) campaign(elector Elector) WorkerFunc {
+	hostname, _ := os.Hostname()
+	val := fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	return func(ctx context.Context) error {
+		if err := elector.Campaign(ctx, val); err != nil {
+			return err
+		}
+		eng.logger.Infod("become leader", logger.FieldString("id", val))
+		eng.setLeader(true)
+		defer eng.setLeader(false)
+
+		leadCtx, cancel := context.WithCancel(ctx)
+		var wg sync.WaitGroup
+		eng.rw.RLock()
+		for _, fn := range eng.leaders {
+			wg.Add(1)
+			go eng.runLeader(leadCtx, &wg, fn)
+		}
+		eng.rw.RUnlock()
+
+		select {
+		case <-elector.Done():
+		case <-ctx.Done():
+		}
+		cancel()
+		wg.Wait()
+		// 引擎停止时主动放弃leader身份，让其他实例尽快接管
+		if ctx.Err() != nil {
+			resignCtx, resignCancel := context.WithTimeout(context.Background(), 3*time.Second)
+			defer resignCancel()
+			if err := elector.Resign(resignCtx); err != nil {
+				eng.logger.Errord("resign leader", logger.FieldErr(err))
+			}
+			return nil
+		}
+		eng.logger.Warnd("lost leader", logger.FieldString("id", val))
+		return errLeadershipLost
+	}
+}
+
+// runLeader 运行leader方法并恢复panic
+func (eng *Engine) runLeader(ctx context.Context, wg *sync.WaitGroup, fn LeaderFunc) {
+	defer wg.Done()
+	defer func() {
+		if rec := recover(); rec != nil {
+			eng.logger.Errord("leader func panic", logger.FieldAny("err", rec), logger.FieldAny("stack", string(debug.Stack())))
+		}
+	}()
+	fn(ctx)
+}
+
+// setLeader 设置leader状态
+func (eng *Engine) setLeader(leader bool) {
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	eng.leader = leader
+}
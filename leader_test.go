@@ -0,0 +1,95 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type fakeElector struct {
+	done    chan struct{}
+	resigns int
+}
+
+func (f *fakeElector) Campaign(ctx context.Context, _ string) error {
+	return ctx.Err()
+}
+
+func (f *fakeElector) Resign(context.Context) error {
+	f.resigns++
+	return nil
+}
+
+func (f *fakeElector) Done() <-chan struct{} {
+	return f.done
+}
+
+func TestEngineLeaderLost(t *testing.T) {
+	eng := &Engine{}
+	elector := &fakeElector{done: make(chan struct{})}
+	eng.SetElector(elector)
+	leading := make(chan struct{})
+	canceled := make(chan struct{})
+	eng.OnLeader(func(ctx context.Context) {
+		close(leading)
+		<-ctx.Done()
+		close(canceled)
+	})
+	result := make(chan error, 1)
+	go func() {
+		result <- eng.campaign(elector)(context.Background())
+	}()
+	<-leading
+	if !eng.IsLeader() {
+		t.Fatal("engine should be leader")
+	}
+	close(elector.done)
+	select {
+	case <-canceled:
+	case <-time.After(time.Second):
+		t.Fatal("leader context not canceled")
+	}
+	if err := <-result; err != errLeadershipLost {
+		t.Fatalf("err = %v, want %v", err, errLeadershipLost)
+	}
+	if eng.IsLeader() {
+		t.Fatal("engine should not be leader")
+	}
+}
+
+func TestEngineLeaderStop(t *testing.T) {
+	eng := &Engine{}
+	elector := &fakeElector{done: make(chan struct{})}
+	eng.SetElector(elector)
+	eng.OnLeader(func(ctx context.Context) {
+		<-ctx.Done()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		result <- eng.campaign(elector)(ctx)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+	if elector.resigns != 1 {
+		t.Fatalf("resigns = %d, want 1", elector.resigns)
+	}
+}
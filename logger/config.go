@@ -16,6 +16,7 @@
 package logger
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/logger/writer"
 	"go.uber.org/zap"
@@ -25,18 +26,19 @@ import (
 
 type Config struct {
 	rw            *sync.RWMutex // 读写锁
-	Debug         bool          `json:"debug"`       // 是否开启debug模式，默认false
-	Stdout        bool          `json:"stdout"`      // 终端输出日志
-	Level         string        `json:"level"`       // 日志等级
-	Fields        []zap.Field   `json:"fields"`      // 初始化字段
-	AddCaller     bool          `json:"add_caller"`  // 是否打印调用者信息，默认，true
-	TimeFormat    string        `json:"time_format"` // 时间格式化
-	CallerSkip    int           `json:"caller_skip"` // 表示输出当前栈帧，默认，1
+	Debug         bool          `json:"debug" usage:"是否开启debug模式，默认false"`
+	Stdout        bool          `json:"stdout" usage:"终端输出日志"`
+	Level         string        `json:"level" usage:"日志等级"`
+	Fields        []zap.Field   `json:"fields" usage:"初始化字段"`
+	AddCaller     bool          `json:"add_caller" usage:"是否打印调用者信息，默认，true"`
+	TimeFormat    string        `json:"time_format" usage:"时间格式化"`
+	CallerSkip    int           `json:"caller_skip" usage:"表示输出当前栈帧，默认，1"`
 	autoLevelKey  string        // 日志等级监听key
+	source        config.Config // 监听日志等级的配置管理器，默认为全局配置
 	Core          zapcore.Core
-	EncoderConfig *zapcore.EncoderConfig   `json:"encoder_config"` // 日志编码设置
+	EncoderConfig *zapcore.EncoderConfig   `json:"encoder_config" usage:"日志编码设置"`
 	writer        map[string]writer.Writer // 日志输出者
-	Writers       map[string]interface{}   `json:"writers"` // 配置信息
+	Writers       map[string]interface{}   `json:"writers" usage:"配置信息"`
 }
 
 // 获取一个默认的配置
@@ -54,8 +56,14 @@ func defaultConfig() *Config {
 
 // RawConfig 根据key构建配置
 func RawConfig(key string) *Config {
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
 	conf := defaultConfig()
-	if err := config.Get(key).Scan(conf); err != nil {
+	conf.source = config.FromContext(ctx)
+	if err := conf.source.Get(key).Scan(conf); err != nil {
 		panic(err)
 	}
 	return conf
@@ -63,13 +71,36 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 根据name构建配置
 func ScanConfig(name string) *Config {
-	conf := RawConfig("ceres.logger." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	conf := RawConfigContext(ctx, "ceres.logger."+name)
 	if conf.TimeFormat != "" {
 		conf.EncoderConfig.EncodeTime = timeEncoderStr(conf.TimeFormat)
 	}
 	return conf
 }
 
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.logger",
+		Named:       true,
+		Description: "logger",
+		Default: func() interface{} {
+			// 编码设置中的编码方法无法序列化，模板中使用默认值
+			conf := defaultConfig()
+			conf.EncoderConfig = nil
+			return conf
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
+}
+
 // initialize 初始化
 func (c *Config) initialize() {
 	if c.rw == nil {
@@ -107,12 +138,22 @@ func (c *Config) AddWriter(writer writer.Writer) *Config {
 	return c
 }
 
+// WithAutoLevel 设置日志等级监听key，该key的配置变化时自动更新等级
+func (c *Config) WithAutoLevel(key string) *Config {
+	c.autoLevelKey = key
+	return c
+}
+
 // Build 创建logger
 func (c Config) Build() *Logger {
 	c.initialize()
 	logger := newLogger(&c)
 	if c.autoLevelKey != "" {
-		logger.AutoLevel(c.autoLevelKey)
+		source := c.source
+		if source == nil {
+			source = config.DefaultConfig
+		}
+		logger.WatchLevel(source, c.autoLevelKey)
 	}
 	return logger
 }
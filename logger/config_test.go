@@ -14,3 +14,33 @@
 //    limitations under the License.
 
 package logger
+
+import (
+	"context"
+	"github.com/go-ceres/go-ceres/config"
+	"go.uber.org/zap/zapcore"
+	"testing"
+)
+
+func TestConfig_WatchLevel(t *testing.T) {
+	c := config.NewConfig()
+	if err := c.Load([]byte(`{"ceres":{"logger":{"app":{"level":"info"}}}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	// 日志等级从上下文中的配置管理器监听，而不是全局配置
+	l := ScanConfigContext(config.NewContext(context.Background(), c), "app").
+		WithAutoLevel("ceres.logger.app.level").
+		Build()
+	if err := c.Set("ceres.logger.app.level", "error"); err != nil {
+		t.Fatal(err)
+	}
+	if lv := l.lv.Level(); lv != zapcore.ErrorLevel {
+		t.Fatalf("level = %s, want error", lv)
+	}
+	if err := config.Set("ceres.logger.app.level", "debug"); err != nil {
+		t.Fatal(err)
+	}
+	if lv := l.lv.Level(); lv != zapcore.ErrorLevel {
+		t.Fatalf("level = %s, global config should not change the level", lv)
+	}
+}
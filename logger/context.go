@@ -0,0 +1,52 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package logger
+
+import "context"
+
+// contextKey 上下文中保存日志的key
+type contextKey struct{}
+
+// loggers 框架日志和项目日志
+type loggers struct {
+	frame *Logger
+	def   *Logger
+}
+
+// NewContext 返回携带框架日志和项目日志的上下文，为nil时使用全局日志
+func NewContext(ctx context.Context, frame, def *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, loggers{frame: frame, def: def})
+}
+
+// FromContext 从上下文中获取项目日志，不存在时返回DefaultLogger
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(loggers); ok && l.def != nil {
+			return l.def
+		}
+	}
+	return DefaultLogger
+}
+
+// FrameFromContext 从上下文中获取框架日志，不存在时返回FrameLogger
+func FrameFromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(contextKey{}).(loggers); ok && l.frame != nil {
+			return l.frame
+		}
+	}
+	return FrameLogger
+}
@@ -136,9 +136,15 @@ func newLogger(c *Config) *Logger {
 	}
 }
 
+// AutoLevel 全局配置中的日志等级变化时自动更新等级
 func (l *Logger) AutoLevel(key string) {
-	config.OnChange(func(v config.Values) {
-		lvText := strings.ToLower(v.Get(key).String(""))
+	l.WatchLevel(config.DefaultConfig, key)
+}
+
+// WatchLevel 指定配置管理器中的日志等级变化时自动更新等级
+func (l *Logger) WatchLevel(c config.Config, key string) {
+	c.WatchPath(key, func(_, v config.Value) {
+		lvText := strings.ToLower(v.String(""))
 		if lvText != "" {
 			l.Info("update level", String("level", lvText))
 			if err := l.lv.UnmarshalText([]byte(lvText)); err != nil {
@@ -0,0 +1,88 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"github.com/go-ceres/go-ceres/cmd"
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/health"
+	"github.com/go-ceres/go-ceres/logger"
+)
+
+// Option 引擎选项，用于在同一进程中运行多个互不影响的引擎，
+// 地域、应用名称等命令行信息以及平滑升级继承的监听仍然是进程级的
+type Option func(eng *Engine)
+
+// WithConfig 使用独立的配置管理器，默认为config.DefaultConfig
+func WithConfig(c config.Config) Option {
+	return func(eng *Engine) {
+		eng.conf = c
+	}
+}
+
+// WithLogger 使用独立的框架日志和项目日志，为nil时根据配置构建，未配置则使用全局日志
+func WithLogger(frame, def *logger.Logger) Option {
+	return func(eng *Engine) {
+		eng.frameLogger = frame
+		eng.defaultLogger = def
+	}
+}
+
+// WithCmd 使用独立的命令行，默认为cmd.DefaultCmd
+func WithCmd(c *cmd.Command) Option {
+	return func(eng *Engine) {
+		eng.command = c
+	}
+}
+
+// WithHealth 使用独立的健康检查组件，默认为health.DefaultHealth
+func WithHealth(h *health.Health) Option {
+	return func(eng *Engine) {
+		eng.health = h
+	}
+}
+
+// WithoutCmd 不解析命令行参数也不初始化插件，用于测试等配置已就绪的场景
+func WithoutCmd() Option {
+	return func(eng *Engine) {
+		eng.withoutCmd = true
+	}
+}
+
+// NewEngine 根据选项创建引擎，需要调用MustSetup完成初始化
+func NewEngine(opts ...Option) *Engine {
+	eng := &Engine{}
+	for _, opt := range opts {
+		opt(eng)
+	}
+	eng.initialize()
+	return eng
+}
+
+// Context 获取携带引擎配置、日志、命令行和健康检查的上下文，引擎停止时取消，
+// 组件通过ScanConfigContext等方法从该上下文中读取配置
+func (eng *Engine) Context() context.Context {
+	ctx := config.NewContext(eng.ctx, eng.conf)
+	ctx = logger.NewContext(ctx, eng.frameLogger, eng.defaultLogger)
+	ctx = health.NewContext(ctx, eng.health)
+	return cmd.NewContext(ctx, eng.command)
+}
+
+// Config 获取引擎的配置管理器
+func (eng *Engine) Config() config.Config {
+	return eng.conf
+}
@@ -0,0 +1,59 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"github.com/go-ceres/go-ceres/cmd"
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/health"
+	"github.com/go-ceres/go-ceres/server/admin"
+	"testing"
+)
+
+func TestEngineScopedConfig(t *testing.T) {
+	c1, c2 := config.NewConfig(), config.NewConfig()
+	if err := c1.Load([]byte(`{"ceres":{"admin":{"port":6001}}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c2.Load([]byte(`{"ceres":{"admin":{"port":6002}}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	eng1, eng2 := NewEngine(WithConfig(c1)), NewEngine(WithConfig(c2))
+	if port := admin.ScanConfigContext(eng1.Context()).Port; port != 6001 {
+		t.Fatalf("port = %d, want 6001", port)
+	}
+	if port := admin.ScanConfigContext(eng2.Context()).Port; port != 6002 {
+		t.Fatalf("port = %d, want 6002", port)
+	}
+	if eng := NewEngine(); eng.Config() != config.DefaultConfig {
+		t.Fatal("engine without options should use the default config")
+	}
+}
+
+func TestEngineScopedHealthAndPlugins(t *testing.T) {
+	h := health.New()
+	plugins := cmd.NewPluginManager()
+	eng := NewEngine(WithHealth(h), WithCmd(cmd.New(cmd.WithPlugins(plugins))))
+	if eng.Health() != h || health.FromContext(eng.Context()) != h {
+		t.Fatal("engine context should carry its own health")
+	}
+	if cmd.FromContext(eng.Context()).Plugins() != plugins {
+		t.Fatal("engine command should use its own plugin manager")
+	}
+	if NewEngine().Health() != health.DefaultHealth {
+		t.Fatal("engine without options should use the default health")
+	}
+}
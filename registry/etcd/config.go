@@ -16,6 +16,7 @@
 package etcd
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/client/etcd"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
@@ -27,31 +28,41 @@ import (
 // Config 配置信息
 type Config struct {
 	*etcd.Config
-	Prefix        string        `json:"prefix"`       // 前缀
-	Namespace     string        `json:"namespace"`    // 服务空间
-	ReadTimeout   time.Duration `json:"read_timeout"` // 请求超时时间
-	ServiceTTL    time.Duration `json:"service_ttl"`  // 服务续约时间间隔
-	EtcdConfigKey string        `json:"etcd_key"`     // etcd配置键
+	Prefix        string        `json:"prefix" usage:"前缀"`
+	Namespace     string        `json:"namespace" usage:"服务空间"`
+	ReadTimeout   time.Duration `json:"read_timeout" usage:"请求超时时间"`
+	ServiceTTL    time.Duration `json:"service_ttl" usage:"服务续约时间间隔"`
+	EtcdConfigKey string        `json:"etcd_key" usage:"etcd配置键"`
 	etcdClient    *etcd.Client
 	log           *logger.Logger
 }
 
 // DefaultConfig 默认配置
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Config:      etcd.DefaultConfig(),
 		Prefix:      "/ceres/registry/",
 		Namespace:   "go-ceres.com",
 		ReadTimeout: time.Second * 3,
 		ServiceTTL:  time.Second * 15,
-		log:         logger.FrameLogger.With(logger.FieldMod(errors.ModRegistryEtcd)),
+		log:         logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModRegistryEtcd)),
 	}
 }
 
 // RawConfig 扫描配置
 func RawConfig(key string) *Config {
-	c := DefaultConfig()
-	err := config.Get(key).Scan(c)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	c := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(c)
 	if err != nil {
 		c.log.Panicd("parse config", logger.FieldMod(errors.ModRegistryEtcd), logger.FieldErr(err), logger.FieldAny("key", key), logger.FieldValue(c))
 	}
@@ -60,7 +71,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 扫描配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.registry." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.registry."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.registry",
+		Named:       true,
+		Description: "etcd service registry",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithLogger 单独设置日志
@@ -0,0 +1,263 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package memory
+
+import (
+	"github.com/go-ceres/go-ceres/registry"
+	"sync"
+)
+
+// memoryRegistry 进程内的注册中心，用于测试和单机运行
+type memoryRegistry struct {
+	rw       sync.RWMutex
+	services map[string][]*registry.Service // 服务名称 -> 各版本服务
+	watchers map[string]map[*watcher]struct{}
+	closed   bool
+}
+
+// watcher 服务监听者
+type watcher struct {
+	scheme string
+	ch     chan registry.WatchServiceResult
+}
+
+// NewRegistry 创建进程内注册中心
+func NewRegistry() registry.Registry {
+	return &memoryRegistry{
+		services: make(map[string][]*registry.Service),
+		watchers: make(map[string]map[*watcher]struct{}),
+	}
+}
+
+// String 获取注册中心实例名称
+func (m *memoryRegistry) String() string {
+	return "memory"
+}
+
+// Register 注册服务，相同id的节点会被覆盖
+func (m *memoryRegistry) Register(srv *registry.Service, _ ...registry.RegisterOption) error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	service := m.find(srv.Name, srv.Version, srv.Scheme)
+	if service == nil {
+		service = &registry.Service{
+			Name:     srv.Name,
+			Version:  srv.Version,
+			Scheme:   srv.Scheme,
+			Metadata: srv.Metadata,
+		}
+		m.services[srv.Name] = append(m.services[srv.Name], service)
+	}
+	for _, node := range srv.Nodes {
+		replaced := false
+		for i, cur := range service.Nodes {
+			if cur.Id == node.Id {
+				service.Nodes[i] = copyNode(node)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			service.Nodes = append(service.Nodes, copyNode(node))
+		}
+	}
+	m.notify(srv.Name)
+	return nil
+}
+
+// Deregister 注销服务节点，没有节点的服务会被删除
+func (m *memoryRegistry) Deregister(srv *registry.Service, _ ...registry.DeRegisterOption) error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	service := m.find(srv.Name, srv.Version, srv.Scheme)
+	if service == nil {
+		return nil
+	}
+	nodes := service.Nodes[:0]
+	for _, cur := range service.Nodes {
+		removed := false
+		for _, node := range srv.Nodes {
+			if cur.Id == node.Id {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			nodes = append(nodes, cur)
+		}
+	}
+	service.Nodes = nodes
+	if len(nodes) == 0 {
+		services := m.services[srv.Name][:0]
+		for _, s := range m.services[srv.Name] {
+			if s != service {
+				services = append(services, s)
+			}
+		}
+		if len(services) == 0 {
+			delete(m.services, srv.Name)
+		} else {
+			m.services[srv.Name] = services
+		}
+	}
+	m.notify(srv.Name)
+	return nil
+}
+
+// GetService 根据名称获取服务列表
+func (m *memoryRegistry) GetService(name string, opts ...registry.GetOption) ([]*registry.Service, error) {
+	opt := &registry.GetOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	res := make([]*registry.Service, 0)
+	for _, s := range m.snapshot(name, opt.Scheme) {
+		s := s
+		res = append(res, &s)
+	}
+	if len(res) == 0 {
+		return nil, registry.ErrNotFound
+	}
+	return res, nil
+}
+
+// WatchService 监听服务，立即返回当前的服务列表，之后每次变化返回完整列表
+func (m *memoryRegistry) WatchService(opts ...registry.WatchOption) (chan registry.WatchServiceResult, error) {
+	opt := &registry.WatchOptions{}
+	for _, o := range opts {
+		o(opt)
+	}
+	if len(opt.Service) == 0 {
+		return nil, registry.ErrNoServiceName
+	}
+	w := &watcher{
+		scheme: opt.Scheme,
+		ch:     make(chan registry.WatchServiceResult, 10),
+	}
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	if m.closed {
+		close(w.ch)
+		return w.ch, nil
+	}
+	if m.watchers[opt.Service] == nil {
+		m.watchers[opt.Service] = make(map[*watcher]struct{})
+	}
+	m.watchers[opt.Service][w] = struct{}{}
+	w.ch <- registry.WatchServiceResult{Services: m.snapshot(opt.Service, opt.Scheme)}
+	if opt.Context != nil {
+		go func() {
+			<-opt.Context.Done()
+			m.unwatch(opt.Service, w)
+		}()
+	}
+	return w.ch, nil
+}
+
+// ListService 获取所有服务
+func (m *memoryRegistry) ListService() ([]*registry.Service, error) {
+	m.rw.RLock()
+	defer m.rw.RUnlock()
+	res := make([]*registry.Service, 0)
+	for name := range m.services {
+		for _, s := range m.snapshot(name, "") {
+			s := s
+			res = append(res, &s)
+		}
+	}
+	return res, nil
+}
+
+// Close 关闭注册中心，关闭所有监听
+func (m *memoryRegistry) Close() error {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	if m.closed {
+		return nil
+	}
+	m.closed = true
+	for name, watchers := range m.watchers {
+		for w := range watchers {
+			close(w.ch)
+		}
+		delete(m.watchers, name)
+	}
+	return nil
+}
+
+// unwatch 取消监听
+func (m *memoryRegistry) unwatch(name string, w *watcher) {
+	m.rw.Lock()
+	defer m.rw.Unlock()
+	if _, ok := m.watchers[name][w]; ok {
+		delete(m.watchers[name], w)
+		close(w.ch)
+	}
+}
+
+// find 查找服务，调用方需持有锁
+func (m *memoryRegistry) find(name, version, scheme string) *registry.Service {
+	for _, s := range m.services[name] {
+		if s.Version == version && s.Scheme == scheme {
+			return s
+		}
+	}
+	return nil
+}
+
+// snapshot 复制服务列表，调用方需持有锁
+func (m *memoryRegistry) snapshot(name, scheme string) []registry.Service {
+	res := make([]registry.Service, 0)
+	for _, s := range m.services[name] {
+		if scheme != "" && s.Scheme != scheme {
+			continue
+		}
+		cp := *s
+		cp.Nodes = make([]*registry.Node, 0, len(s.Nodes))
+		for _, node := range s.Nodes {
+			cp.Nodes = append(cp.Nodes, copyNode(node))
+		}
+		res = append(res, cp)
+	}
+	return res
+}
+
+// notify 通知监听者服务变化，监听者未及时读取时丢弃最旧的结果，调用方需持有锁
+func (m *memoryRegistry) notify(name string) {
+	for w := range m.watchers[name] {
+		res := registry.WatchServiceResult{Services: m.snapshot(name, w.scheme)}
+		select {
+		case w.ch <- res:
+		default:
+			select {
+			case <-w.ch:
+			default:
+			}
+			select {
+			case w.ch <- res:
+			default:
+			}
+		}
+	}
+}
+
+// copyNode 复制节点信息
+func copyNode(node *registry.Node) *registry.Node {
+	cp := *node
+	return &cp
+}
@@ -0,0 +1,66 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package memory
+
+import (
+	"github.com/go-ceres/go-ceres/registry"
+	"testing"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	ch, err := r.WatchService(registry.WatchService("demo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res := <-ch; len(res.Services) != 0 {
+		t.Fatalf("services = %v, want empty", res.Services)
+	}
+	srv := &registry.Service{
+		Name:    "demo",
+		Version: "v1",
+		Scheme:  "grpc",
+		Nodes:   []*registry.Node{{Id: "1", Address: "127.0.0.1:1"}},
+	}
+	if err := r.Register(srv); err != nil {
+		t.Fatal(err)
+	}
+	srv.Nodes = []*registry.Node{{Id: "2", Address: "127.0.0.1:2"}}
+	if err := r.Register(srv); err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	if res := <-ch; len(res.Services) != 1 || len(res.Services[0].Nodes) != 2 {
+		t.Fatalf("unexpected watch result %+v", res)
+	}
+	if _, err := r.GetService("demo", registry.GetScheme("http")); err != registry.ErrNotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+
+	if err := r.Deregister(&registry.Service{Name: "demo", Version: "v1", Scheme: "grpc", Nodes: []*registry.Node{{Id: "1"}, {Id: "2"}}}); err != nil {
+		t.Fatal(err)
+	}
+	if res := <-ch; len(res.Services) != 0 {
+		t.Fatalf("services = %v, want empty", res.Services)
+	}
+	if _, err := r.GetService("demo"); err != registry.ErrNotFound {
+		t.Fatalf("err = %v, want not found", err)
+	}
+	_ = r.Close()
+	if _, ok := <-ch; ok {
+		t.Fatal("watch channel should be closed")
+	}
+}
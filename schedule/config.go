@@ -16,31 +16,42 @@
 package schedule
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/logger"
 	"github.com/robfig/cron/v3"
 )
 
 type Config struct {
-	Size int         `json:"size"` // 任务总数量
+	Size int         `json:"size" usage:"任务总数量"`
 	log  cron.Logger // 日志组件
 	Opts []cron.Option
 }
 
 // DefaultConfig 默认配置
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Size: 100,
 		log: &Logger{
-			Log: logger.FrameLogger,
+			Log: logger.FrameFromContext(ctx),
 		},
 	}
 }
 
 // RawConfig 完整key取配置
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	err := config.Get(key).Scan(conf)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(conf)
 	if err != nil {
 		panic(err)
 	}
@@ -49,7 +60,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 名称取配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.cron." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.cron."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.cron",
+		Named:       true,
+		Description: "cron scheduler",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithOptions 设置额外参数
@@ -0,0 +1,210 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-ceres/go-ceres/cmd"
+	"github.com/go-ceres/go-ceres/logger"
+	"github.com/go-ceres/go-ceres/server"
+	"github.com/go-ceres/go-ceres/utils/upgradex"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"sync"
+)
+
+// JSONHandler 返回json数据的处理方法
+type JSONHandler func(r *http.Request) (interface{}, error)
+
+// CheckFunc 健康检查方法，返回检查详情，返回错误表示不健康
+type CheckFunc func(ctx context.Context) (interface{}, error)
+
+// Server 管理服务，提供pprof、健康检查、配置等运维接口
+type Server struct {
+	mu       sync.RWMutex
+	mux      *http.ServeMux
+	Server   *http.Server
+	listener net.Listener
+	Config   *Config
+	live     CheckFunc
+	ready    CheckFunc
+}
+
+// newServer 创建管理服务
+func newServer(c *Config) *Server {
+	listener, err := upgradex.Listen("tcp", c.Address())
+	if err != nil {
+		c.logger.Panicd("new admin server error", logger.FieldErr(err))
+	}
+	c.Port = listener.Addr().(*net.TCPAddr).Port
+	s := &Server{
+		mux:      http.NewServeMux(),
+		Config:   c,
+		listener: listener,
+	}
+	s.Server = &http.Server{
+		Addr:    c.Address(),
+		Handler: s.mux,
+	}
+	s.HandleJSON("/health/live", s.handleLive)
+	s.HandleJSON("/health/ready", s.handleReady)
+	s.HandleJSON("/config", s.handleConfig)
+	s.HandleJSON("/buildinfo", s.handleBuildInfo)
+	if c.Pprof {
+		s.mux.HandleFunc("/debug/pprof/", pprof.Index)
+		s.mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+		s.mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+		s.mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+		s.mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	}
+	return s
+}
+
+// Handle 添加自定义接口
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mux.Handle(pattern, handler)
+}
+
+// HandleJSON 添加返回json数据的接口
+func (s *Server) HandleJSON(pattern string, handler JSONHandler) {
+	s.mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		data, err := handler(r)
+		if err != nil {
+			code := http.StatusInternalServerError
+			if he, ok := err.(*statusError); ok {
+				code = he.code
+				data = he.data
+			} else {
+				data = map[string]string{"error": err.Error()}
+			}
+			writeJSON(w, code, data)
+			return
+		}
+		writeJSON(w, http.StatusOK, data)
+	})
+}
+
+// SetLiveness 设置存活检查
+func (s *Server) SetLiveness(fn CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.live = fn
+}
+
+// SetReadiness 设置就绪检查
+func (s *Server) SetReadiness(fn CheckFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ready = fn
+}
+
+// Start 启动服务
+func (s *Server) Start() error {
+	s.Config.logger.Infod("start admin server", logger.FieldString("address", s.listener.Addr().String()))
+	err := s.Server.Serve(s.listener)
+	if err == http.ErrServerClosed {
+		s.Config.logger.Infod("admin server close", logger.FieldString("address", s.Config.Address()))
+		return nil
+	}
+	return err
+}
+
+// Stop 停止服务
+func (s *Server) Stop() error {
+	return s.Server.Close()
+}
+
+// GracefulStop 优雅停止服务
+func (s *Server) GracefulStop(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
+
+// Info 服务信息
+func (s *Server) Info() *server.ServiceInfo {
+	address := s.listener.Addr().String()
+	return server.ApplyOptions(
+		server.WithAddress(address),
+		server.WithScheme("http"),
+		server.WithMetadata("app_host", address),
+	)
+}
+
+// handleLive 存活检查
+func (s *Server) handleLive(r *http.Request) (interface{}, error) {
+	s.mu.RLock()
+	fn := s.live
+	s.mu.RUnlock()
+	return check(r.Context(), fn)
+}
+
+// handleReady 就绪检查
+func (s *Server) handleReady(r *http.Request) (interface{}, error) {
+	s.mu.RLock()
+	fn := s.ready
+	s.mu.RUnlock()
+	return check(r.Context(), fn)
+}
+
+// handleConfig 当前生效的配置，敏感信息已脱敏
+func (s *Server) handleConfig(_ *http.Request) (interface{}, error) {
+	return s.Config.values.Masked(), nil
+}
+
+// handleBuildInfo 构建信息
+func (s *Server) handleBuildInfo(_ *http.Request) (interface{}, error) {
+	return cmd.BuildInfo(), nil
+}
+
+// check 运行检查方法，有检查详情时直接输出详情
+func check(ctx context.Context, fn CheckFunc) (interface{}, error) {
+	if fn == nil {
+		return map[string]string{"status": "UP"}, nil
+	}
+	data, err := fn(ctx)
+	if err != nil {
+		if data == nil {
+			data = map[string]string{"status": "DOWN", "error": err.Error()}
+		}
+		return nil, &statusError{
+			code: http.StatusServiceUnavailable,
+			data: data,
+		}
+	}
+	if data == nil {
+		data = map[string]string{"status": "UP"}
+	}
+	return data, nil
+}
+
+// statusError 带状态码的错误
+type statusError struct {
+	code int
+	data interface{}
+}
+
+// Error 实现error接口
+func (e *statusError) Error() string {
+	return http.StatusText(e.code)
+}
+
+// writeJSON 输出json
+func writeJSON(w http.ResponseWriter, code int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(data)
+}
@@ -0,0 +1,120 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/logger"
+)
+
+// Config 管理服务配置
+type Config struct {
+	Enable bool   `json:"enable" usage:"是否开启管理服务"`
+	Host   string `json:"host" usage:"服务ip"`
+	Port   int    `json:"port" usage:"服务端口"`
+	Pprof  bool   `json:"pprof" usage:"是否开启pprof"`
+	logger *logger.Logger
+	values config.Config // 通过/config接口展示的配置
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
+	return &Config{
+		Enable: false,
+		Host:   "0.0.0.0",
+		Port:   5203,
+		Pprof:  true,
+		logger: logger.FrameFromContext(ctx).With(logger.FieldMod("server.admin")),
+		values: config.FromContext(ctx),
+	}
+}
+
+// RawConfig 根据key解析配置
+func RawConfig(key string) *Config {
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	if err := config.FromContext(ctx).Get(key).Scan(conf); err != nil {
+		conf.logger.Panicd(
+			"admin server parse config panic",
+			logger.FieldErr(err),
+			logger.FieldValue(conf),
+		)
+	}
+	return conf
+}
+
+// ScanConfig 解析标准配置
+func ScanConfig() *Config {
+	return ScanConfigContext(context.Background())
+}
+
+// ScanConfigContext 从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context) *Config {
+	return RawConfigContext(ctx, "ceres.admin")
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.admin",
+		Description: "admin server with health, config and pprof endpoints",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, _ string) interface{} {
+			return ScanConfigContext(ctx)
+		},
+	})
+}
+
+// WithLogger 设置日志组件
+func (c *Config) WithLogger(log *logger.Logger) *Config {
+	c.logger = log
+	return c
+}
+
+// WithHost 设置服务ip
+func (c *Config) WithHost(host string) *Config {
+	c.Host = host
+	return c
+}
+
+// WithPort 设置服务端口
+func (c *Config) WithPort(port int) *Config {
+	c.Port = port
+	return c
+}
+
+// Address 服务地址
+func (c *Config) Address() string {
+	return fmt.Sprintf("%s:%d", c.Host, c.Port)
+}
+
+// Build 构建管理服务
+func (c *Config) Build() *Server {
+	return newServer(c)
+}
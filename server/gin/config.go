@@ -16,6 +16,7 @@
 package gin
 
 import (
+	"context"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"github.com/go-ceres/go-ceres/cmd"
@@ -24,33 +25,43 @@ import (
 )
 
 type Config struct {
-	Host                string // 服务ip
-	Port                int    // 服务端口
-	Mode                string // 运行模式
-	PlainTextAddress    string // 注册中心显示地址
-	Version             string // 当前项目版本号
-	Name                string // 服务名称
-	ServerSlowThreshold int64  // 服务器超时阈值
+	Host                string `usage:"服务ip"`
+	Port                int    `usage:"服务端口"`
+	Mode                string `usage:"运行模式"`
+	PlainTextAddress    string `usage:"注册中心显示地址"`
+	Version             string `usage:"当前项目版本号"`
+	Name                string `usage:"服务名称"`
+	ServerSlowThreshold int64  `usage:"服务器超时阈值"`
 	logger              *logger.Logger
 }
 
 // DefaultConfig 默认配置
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Host:                "0.0.0.0",
 		Port:                5202,
 		ServerSlowThreshold: 500,
 		Mode:                gin.ReleaseMode,
-		logger:              logger.FrameLogger.With(logger.FieldMod("server.gin")),
-		Name:                cmd.DefaultCmd.App().Name,
-		Version:             cmd.DefaultCmd.App().Version,
+		logger:              logger.FrameFromContext(ctx).With(logger.FieldMod("server.gin")),
+		Name:                cmd.FromContext(ctx).App().Name,
+		Version:             cmd.FromContext(ctx).App().Version,
 	}
 }
 
 // RawConfig 读取配置
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	if err := config.Get(key).Scan(conf); err != nil {
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	if err := config.FromContext(ctx).Get(key).Scan(conf); err != nil {
 		conf.logger.Panicd(
 			"grpc server parse config panic",
 			logger.FieldErr(err),
@@ -62,7 +73,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 从config组件读取配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.server.gin." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.server.gin."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.server.gin",
+		Named:       true,
+		Description: "gin http server",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithLogger 重新设置日志
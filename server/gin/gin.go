@@ -16,10 +16,12 @@
 package gin
 
 import (
+	"context"
 	"github.com/gin-gonic/gin"
 	"github.com/go-ceres/go-ceres/errors"
 	"github.com/go-ceres/go-ceres/logger"
 	"github.com/go-ceres/go-ceres/server"
+	"github.com/go-ceres/go-ceres/utils/upgradex"
 	"net"
 	"net/http"
 	"reflect"
@@ -52,7 +54,7 @@ type RouterDesc struct {
 
 // newGinServer 创建gin服务
 func newGinServer(config *Config) *Server {
-	listener, err := net.Listen("tcp", config.Address())
+	listener, err := upgradex.Listen("tcp", config.Address())
 	if err != nil {
 		config.logger.Panicd("new gin server error", logger.FieldErr(err))
 	}
@@ -87,6 +89,7 @@ func (s *Server) Start() error {
 	err := s.Server.Serve(s.listener)
 	if err == http.ErrServerClosed {
 		s.Config.logger.Infod("gin server close", logger.FieldString("address", s.Config.Address()))
+		return nil
 	}
 	return err
 }
@@ -96,9 +99,24 @@ func (s *Server) Stop() error {
 	return s.Server.Close()
 }
 
+// GracefulStop 优雅停止服务，不再接收新请求并等待处理中的请求完成
+func (s *Server) GracefulStop(ctx context.Context) error {
+	return s.Server.Shutdown(ctx)
+}
+
+// ListRoutes 获取所有路由
+func (s *Server) ListRoutes() []server.Route {
+	routes := s.Engine.Routes()
+	res := make([]server.Route, 0, len(routes))
+	for _, route := range routes {
+		res = append(res, server.Route{Method: route.Method, Path: route.Path})
+	}
+	return res
+}
+
 // Info 获取服务信息
 func (s *Server) Info() *server.ServiceInfo {
-	address := s.listener.Addr().String()
+	address, _, _ := net.SplitHostPort(s.listener.Addr().String())
 	if s.Config.PlainTextAddress != "" {
 		address = s.Config.PlainTextAddress
 	}
@@ -16,33 +16,42 @@
 package grpc
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-ceres/go-ceres/cmd"
 	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/health"
 	"github.com/go-ceres/go-ceres/logger"
 	"google.golang.org/grpc"
 )
 
 type Config struct {
-	Debug               bool   // 是否开启调试
-	Network             string // net.listen的network类型
-	Host                string // 服务ip
-	Port                int    // 服务端口
-	PlainTextAddress    string // 注册中心显示的地址
-	Version             string // 当前项目版本号
-	Name                string // 服务名称
-	TLS                 bool   // 是否使用tls连接
-	CertFile            string // tls的cert文件路径
-	KeyFile             string // tls的key文件路径
-	ServerSlowThreshold int64  // 服务器素速度阈值
+	Debug               bool   `usage:"是否开启调试"`
+	Network             string `usage:"net.listen的network类型"`
+	Host                string `usage:"服务ip"`
+	Port                int    `usage:"服务端口"`
+	PlainTextAddress    string `usage:"注册中心显示的地址"`
+	Version             string `usage:"当前项目版本号"`
+	Name                string `usage:"服务名称"`
+	TLS                 bool   `usage:"是否使用tls连接"`
+	CertFile            string `usage:"tls的cert文件路径"`
+	KeyFile             string `usage:"tls的key文件路径"`
+	ServerSlowThreshold int64  `usage:"服务器素速度阈值"`
+	Health              bool   `usage:"是否注册grpc.health.v1健康检查服务"`
 	serverOptions       []grpc.ServerOption
 	streamInterceptors  []grpc.StreamServerInterceptor
 	unaryInterceptors   []grpc.UnaryServerInterceptor
 	logger              *logger.Logger // 日志组件
+	health              *health.Health // 健康检查组件
 }
 
 // DefaultConfig 默认配置
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Debug:               true,
 		Network:             "tcp4",
@@ -51,16 +60,23 @@ func DefaultConfig() *Config {
 		CertFile:            "",
 		KeyFile:             "",
 		ServerSlowThreshold: 500,
-		logger:              logger.FrameLogger.With(logger.FieldMod("server.grpc")),
-		Name:                cmd.DefaultCmd.App().Name,
-		Version:             cmd.DefaultCmd.App().Version,
+		Health:              true,
+		health:              health.FromContext(ctx),
+		logger:              logger.FrameFromContext(ctx).With(logger.FieldMod("server.grpc")),
+		Name:                cmd.FromContext(ctx).App().Name,
+		Version:             cmd.FromContext(ctx).App().Version,
 	}
 }
 
 // RawConfig 读取配置
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	if err := config.Get(key).Scan(conf); err != nil {
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	if err := config.FromContext(ctx).Get(key).Scan(conf); err != nil {
 		conf.logger.Panicd(
 			"grpc server parse config panic",
 			logger.FieldErr(err),
@@ -72,7 +88,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 从config组件读取配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.server.grpc." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.server.grpc."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.server.grpc",
+		Named:       true,
+		Description: "grpc server",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithServerOption 设置grpc服务参数
@@ -110,6 +146,24 @@ func (c *Config) WithLogger(log *logger.Logger) *Config {
 	return c
 }
 
+// WithHealth 设置健康检查组件
+func (c *Config) WithHealth(h *health.Health) *Config {
+	c.health = h
+	return c
+}
+
+// WithHost 设置主机名
+func (c *Config) WithHost(host string) *Config {
+	c.Host = host
+	return c
+}
+
+// WithPort 设置端口
+func (c *Config) WithPort(port int) *Config {
+	c.Port = port
+	return c
+}
+
 // Address 获取服务地址
 func (c *Config) Address() string {
 	return fmt.Sprintf("%s:%d", c.Host, c.Port)
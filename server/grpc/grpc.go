@@ -16,16 +16,21 @@
 package grpc
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/logger"
 	"github.com/go-ceres/go-ceres/server"
+	"github.com/go-ceres/go-ceres/utils/upgradex"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"net"
+	"sort"
 	"time"
 )
 
 type grpcServer struct {
 	Server   *grpc.Server
 	listener net.Listener
+	health   *healthServer
 	*Config
 }
 
@@ -49,7 +54,12 @@ func newServer(c *Config) *grpcServer {
 	)
 
 	newServer := grpc.NewServer(c.serverOptions...)
-	listener, err := net.Listen(c.Network, c.Address())
+	var hs *healthServer
+	if c.Health && c.health != nil {
+		hs = newHealthServer(c.health)
+		healthpb.RegisterHealthServer(newServer, hs)
+	}
+	listener, err := upgradex.Listen(c.Network, c.Address())
 	if err != nil {
 		c.logger.Panicd("new grpc server err", logger.FieldErr(err))
 	}
@@ -58,6 +68,7 @@ func newServer(c *Config) *grpcServer {
 	return &grpcServer{
 		Server:   newServer,
 		listener: listener,
+		health:   hs,
 		Config:   c,
 	}
 }
@@ -73,6 +84,43 @@ func (s *grpcServer) Stop() error {
 	return nil
 }
 
+// Drain 开始停止，健康检查服务之后均返回NOT_SERVING
+func (s *grpcServer) Drain() {
+	if s.health != nil {
+		s.health.drain()
+	}
+}
+
+// GracefulStop 优雅停止，ctx结束时仍未完成则强制停止
+func (s *grpcServer) GracefulStop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.Server.GracefulStop()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.Server.Stop()
+		return ctx.Err()
+	}
+}
+
+// ListRoutes 获取所有grpc方法
+func (s *grpcServer) ListRoutes() []server.Route {
+	res := make([]server.Route, 0)
+	for name, info := range s.Server.GetServiceInfo() {
+		for _, method := range info.Methods {
+			res = append(res, server.Route{Method: "GRPC", Path: "/" + name + "/" + method.Name})
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+	return res
+}
+
 // Info 服务信息
 func (s *grpcServer) Info() *server.ServiceInfo {
 	address := s.listener.Addr().String()
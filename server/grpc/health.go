@@ -0,0 +1,121 @@
+//   Copyright 2022 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"github.com/go-ceres/go-ceres/health"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"sync"
+)
+
+// healthServer 基于health组件实现的grpc.health.v1服务
+// service为空时返回整体就绪状态，否则返回对应名称检查项的状态，
+// 优先使用最近一次定时刷新的结果，开始停止后均返回NOT_SERVING
+type healthServer struct {
+	healthpb.UnimplementedHealthServer
+	health    *health.Health
+	drainOnce sync.Once
+	draining  chan struct{} // 开始停止时关闭
+}
+
+// newHealthServer 创建grpc健康检查服务
+func newHealthServer(h *health.Health) *healthServer {
+	return &healthServer{health: h, draining: make(chan struct{})}
+}
+
+// drain 标记为正在停止
+func (s *healthServer) drain() {
+	s.drainOnce.Do(func() {
+		close(s.draining)
+	})
+}
+
+// isDraining 是否正在停止
+func (s *healthServer) isDraining() bool {
+	select {
+	case <-s.draining:
+		return true
+	default:
+		return false
+	}
+}
+
+// Check 检查服务状态
+func (s *healthServer) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	report, ok := s.report(ctx, req.GetService())
+	if !ok {
+		return nil, status.Error(codes.NotFound, "unknown service")
+	}
+	return &healthpb.HealthCheckResponse{Status: servingStatus(report)}, nil
+}
+
+// Watch 监听服务状态，状态变化时推送
+func (s *healthServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	changed, cancel := s.health.Subscribe()
+	defer cancel()
+	draining := s.draining
+	last := healthpb.HealthCheckResponse_UNKNOWN
+	for {
+		current := healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		if report, ok := s.report(stream.Context(), req.GetService()); ok {
+			current = servingStatus(report)
+		}
+		if current != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: current}); err != nil {
+				return status.Error(codes.Canceled, "stream has ended")
+			}
+			last = current
+		}
+		select {
+		case <-changed:
+		case <-draining:
+			draining = nil
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		}
+	}
+}
+
+// report 获取检查报告，优先使用缓存的就绪结果，从未检查过时刷新一次
+func (s *healthServer) report(ctx context.Context, service string) (*health.Report, bool) {
+	if s.isDraining() {
+		return &health.Report{Status: health.StatusDown}, true
+	}
+	last := s.health.Last()
+	if last == nil {
+		last = s.health.Refresh(ctx)
+	}
+	if service == "" {
+		return last, true
+	}
+	for _, res := range last.Checks {
+		if res.Name == service {
+			return &health.Report{Status: res.Status, Checks: []health.Result{res}}, true
+		}
+	}
+	return s.health.Check(ctx, service)
+}
+
+// servingStatus 转换为grpc健康状态
+func servingStatus(report *health.Report) healthpb.HealthCheckResponse_ServingStatus {
+	if report.IsUp() {
+		return healthpb.HealthCheckResponse_SERVING
+	}
+	return healthpb.HealthCheckResponse_NOT_SERVING
+}
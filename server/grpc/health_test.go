@@ -0,0 +1,68 @@
+//   Copyright 2022 Go-Ceres
+//   Author https://github.com/go-ceres/go-ceres
+//
+//   Licensed under the Apache License, Version 2.0 (the "License");
+//   you may not use this file except in compliance with the License.
+//   You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+//   Unless required by applicable law or agreed to in writing, software
+//   distributed under the License is distributed on an "AS IS" BASIS,
+//   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//   See the License for the specific language governing permissions and
+//   limitations under the License.
+
+package grpc
+
+import (
+	"context"
+	"errors"
+	"github.com/go-ceres/go-ceres/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"testing"
+)
+
+func TestHealthServer_Check(t *testing.T) {
+	h := health.New()
+	var calls int
+	var down bool
+	_ = h.Register("db", health.CheckerFunc(func(ctx context.Context) error {
+		calls++
+		if down {
+			return errors.New("down")
+		}
+		return nil
+	}))
+	s := newHealthServer(h)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		resp, err := s.Check(ctx, &healthpb.HealthCheckRequest{})
+		if err != nil || resp.Status != healthpb.HealthCheckResponse_SERVING {
+			t.Fatalf("check = %v, %v, want SERVING", resp, err)
+		}
+	}
+	if resp, _ := s.Check(ctx, &healthpb.HealthCheckRequest{Service: "db"}); resp.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Fatalf("check db = %v, want SERVING", resp)
+	}
+	if calls != 1 {
+		t.Fatalf("checker calls = %d, want 1 using cached result", calls)
+	}
+
+	down = true
+	h.Refresh(ctx)
+	if resp, _ := s.Check(ctx, &healthpb.HealthCheckRequest{}); resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("check = %v, want NOT_SERVING after refresh", resp)
+	}
+	if _, err := s.Check(ctx, &healthpb.HealthCheckRequest{Service: "unknown"}); err == nil {
+		t.Fatal("check unknown service should fail")
+	}
+
+	down = false
+	h.Refresh(ctx)
+	s.drain()
+	if resp, _ := s.Check(ctx, &healthpb.HealthCheckRequest{}); resp.Status != healthpb.HealthCheckResponse_NOT_SERVING {
+		t.Fatalf("check = %v, want NOT_SERVING while draining", resp)
+	}
+}
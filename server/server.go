@@ -18,6 +18,7 @@
 package server
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/cmd"
 	"github.com/google/uuid"
 )
@@ -26,12 +27,32 @@ import (
 type Server interface {
 	// Stop t停止
 	Stop() error
+	// GracefulStop 优雅停止，等待正在处理的请求完成，ctx结束时返回
+	GracefulStop(ctx context.Context) error
 	// Start 启动
 	Start() error
 	// Info 服务信息
 	Info() *ServiceInfo
 }
 
+// Drainer 在优雅停止开始时需要感知的服务，例如让健康检查先返回不可用
+type Drainer interface {
+	// Drain 开始停止
+	Drain()
+}
+
+// Route 路由信息
+type Route struct {
+	Method string `json:"method"` // 请求方法，grpc服务为GRPC
+	Path   string `json:"path"`   // 路由路径，grpc服务为完整方法名
+}
+
+// RouteLister 可以列出自身路由的服务
+type RouteLister interface {
+	// ListRoutes 获取服务的所有路由
+	ListRoutes() []Route
+}
+
 // ServiceInfo 服务信息
 type ServiceInfo struct {
 	Id       string            `json:"id"`       // 应用ID
@@ -0,0 +1,217 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"encoding/json"
+	"github.com/go-ceres/go-ceres/config"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultPrefix 默认读取的环境变量前缀
+const DefaultPrefix = "CERES_"
+
+// DefaultMapping 默认的环境变量与配置路径的映射，用于框架中含有大写字母的配置项
+var DefaultMapping = map[string]string{
+	"CERES_APPLICATION_MAX_PROC":     "ceres.application.maxProc",
+	"CERES_APPLICATION_STOP_DELAY":   "ceres.application.stopDelay",
+	"CERES_APPLICATION_STOP_TIMEOUT": "ceres.application.stopTimeout",
+}
+
+// DefaultExcludes 默认不读取的环境变量，这些变量用于启动时加载配置或平滑升级，不属于配置，
+// 以_结尾时表示排除该前缀的所有变量
+var DefaultExcludes = []string{
+	"CERES_CONFIG_FILE",
+	"CERES_CONFIG_WATCH",
+	"CERES_CONFIG_DECODE",
+	"CERES_CONFIG_ENDPOINTS",
+	"CERES_CONFIG_ETCD_",
+	"CERES_CONFIG_ENV_PREFIX",
+	"CERES_CONFIG_ENV_SEPARATOR",
+	"CERES_CONFIG_KEY",
+	"CERES_CONFIG_KEY_FILE",
+	"CERES_PROFILE",
+	"CERES_REGION",
+	"CERES_ZONE",
+	"CERES_UPGRADE_",
+}
+
+type envSource struct {
+	mu            sync.Mutex
+	prefixes      []string
+	stripPrefixes []string
+	separator     string
+	keyMapper     func(key string) string
+	mapping       map[string]string // 环境变量名到配置路径的映射，优先于前缀规则
+	excludes      []string          // 不读取的环境变量
+	environ       func() []string
+	changed       chan struct{}
+}
+
+// Read 读取环境变量并转为json格式的配置
+func (e *envSource) Read() (*config.DataSet, error) {
+	data := make(map[string]interface{})
+	envs := e.environ()
+	sort.Strings(envs)
+	for _, env := range envs {
+		pair := strings.SplitN(env, "=", 2)
+		if len(pair) != 2 {
+			continue
+		}
+		paths, ok := e.paths(pair[0])
+		if !ok {
+			continue
+		}
+		// 值保持字符串，解码时再按目标字段类型转换为数字、布尔值或列表
+		setPath(data, paths, pair[1])
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	cs := &config.DataSet{
+		Format:    "json",
+		Source:    e.String(),
+		Timestamp: time.Now(),
+		Data:      b,
+	}
+	cs.Checksum = cs.Sum()
+	return cs, nil
+}
+
+// Write 环境变量不支持写入
+func (e *envSource) Write(*config.DataSet) error {
+	return config.ErrReadOnly
+}
+
+// IsChanged 进程内环境变量不会变化，通道只会在取消监听时关闭
+func (e *envSource) IsChanged() <-chan struct{} {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.changed
+}
+
+// Watch 开启监听
+func (e *envSource) Watch() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.changed == nil {
+		e.changed = make(chan struct{})
+	}
+}
+
+// UnWatch 取消监听
+func (e *envSource) UnWatch() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.changed != nil {
+		close(e.changed)
+		e.changed = nil
+	}
+}
+
+// String 配置源名称
+func (e *envSource) String() string {
+	return "env"
+}
+
+// paths 将环境变量名转为配置路径
+func (e *envSource) paths(name string) ([]string, bool) {
+	if path, ok := e.mapping[name]; ok {
+		return strings.Split(path, "."), true
+	}
+	if e.excluded(name) {
+		return nil, false
+	}
+	key, ok := "", false
+	for _, prefix := range e.stripPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			key, ok = strings.TrimPrefix(name, prefix), true
+			break
+		}
+	}
+	if !ok {
+		for _, prefix := range e.prefixes {
+			if strings.HasPrefix(name, prefix) {
+				key, ok = name, true
+				break
+			}
+		}
+	}
+	if !ok || key == "" {
+		return nil, false
+	}
+	parts := strings.Split(key, e.separator)
+	paths := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			return nil, false
+		}
+		paths = append(paths, e.keyMapper(part))
+	}
+	return paths, true
+}
+
+// excluded 是否为不读取的环境变量
+func (e *envSource) excluded(name string) bool {
+	for _, exclude := range e.excludes {
+		if name == exclude || strings.HasSuffix(exclude, "_") && strings.HasPrefix(name, exclude) {
+			return true
+		}
+	}
+	return false
+}
+
+// setPath 按路径设置值，同一路径既有值又有子路径时保留子路径
+func setPath(data map[string]interface{}, paths []string, value interface{}) {
+	cur := data
+	for _, p := range paths[:len(paths)-1] {
+		next, ok := cur[p].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			cur[p] = next
+		}
+		cur = next
+	}
+	last := paths[len(paths)-1]
+	if _, ok := cur[last].(map[string]interface{}); ok {
+		return
+	}
+	cur[last] = value
+}
+
+// NewSource 创建环境变量配置源
+func NewSource(opts ...Option) config.Source {
+	e := &envSource{
+		prefixes:  []string{DefaultPrefix},
+		separator: "_",
+		keyMapper: defaultKeyMapper,
+		mapping:   make(map[string]string, len(DefaultMapping)),
+		excludes:  DefaultExcludes,
+		environ:   os.Environ,
+	}
+	for name, path := range DefaultMapping {
+		e.mapping[name] = path
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	return e
+}
@@ -0,0 +1,178 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import (
+	"github.com/go-ceres/go-ceres/config"
+	"testing"
+)
+
+func environ(envs ...string) Option {
+	return Environ(func() []string {
+		return envs
+	})
+}
+
+func TestEnvSource(t *testing.T) {
+	c := config.NewConfig()
+	if err := c.Load([]byte(`{"ceres":{"server":{"gin":{"default":{"host":"0.0.0.0","port":5202}}}}}`), "json"); err != nil {
+		t.Fatal(err)
+	}
+	src := NewSource(environ(
+		"CERES_SERVER_GIN_DEFAULT_PORT=8080",
+		"CERES_SERVER_GIN_DEFAULT_DEBUG=true",
+		"CERES_REGISTRY_ENDPOINTS=127.0.0.1:2379, 127.0.0.1:2380",
+		"CERES_RATIO=0.5",
+		"PATH=/usr/bin",
+	))
+	if err := c.LoadSource(src); err != nil {
+		t.Fatal(err)
+	}
+	if port := c.Get("ceres.server.gin.default.port").Int(0); port != 8080 {
+		t.Fatalf("port = %d, want 8080", port)
+	}
+	if host := c.Get("ceres.server.gin.default.host").String(""); host != "0.0.0.0" {
+		t.Fatalf("host = %s, want 0.0.0.0", host)
+	}
+	if !c.Get("ceres.server.gin.default.debug").Bool(false) {
+		t.Fatal("debug should be true")
+	}
+	var registry struct {
+		Endpoints []string `json:"endpoints"`
+	}
+	if err := c.Get("ceres.registry").Scan(&registry); err != nil {
+		t.Fatal(err)
+	}
+	if endpoints := registry.Endpoints; len(endpoints) != 2 || endpoints[1] != "127.0.0.1:2380" {
+		t.Fatalf("endpoints = %v", endpoints)
+	}
+	if ratio := c.Get("ceres.ratio").Float64(0); ratio != 0.5 {
+		t.Fatalf("ratio = %v, want 0.5", ratio)
+	}
+	if !c.Get("path").IsEmpty() {
+		t.Fatal("variables without prefix should be ignored")
+	}
+	if got := c.SourceOf("ceres.server.gin.default.port"); got != "env" {
+		t.Fatalf("SourceOf = %s, want env", got)
+	}
+}
+
+func TestEnvSourceRules(t *testing.T) {
+	src := NewSource(
+		StripPrefix("APP__"),
+		Separator("__"),
+		environ("APP__CLIENT__DIAL_TIMEOUT=3s", "APP__TAGS=a,b", "CERES_PORT=1"),
+	)
+	c := config.NewConfig()
+	if err := c.LoadSource(src); err != nil {
+		t.Fatal(err)
+	}
+	if timeout := c.Get("client.dial_timeout").String(""); timeout != "3s" {
+		t.Fatalf("dial_timeout = %s, want 3s", timeout)
+	}
+	if tags := c.Get("tags").String(""); tags != "a,b" {
+		t.Fatalf("tags = %s, want a,b", tags)
+	}
+	if !c.Get("ceres.port").IsEmpty() {
+		t.Fatal("default prefix should be replaced by strip prefix")
+	}
+}
+
+func TestEnvSourceStrings(t *testing.T) {
+	src := NewSource(environ(
+		"CERES_REDIS_PASSWORD=123456",
+		"CERES_REDIS_DB=3",
+		"CERES_MYSQL_DSN=root:pass@tcp(127.0.0.1:3306)/ceres?charset=utf8mb4,utf8&parseTime=true",
+	))
+	c := config.NewConfig()
+	if err := c.LoadSource(src); err != nil {
+		t.Fatal(err)
+	}
+	var conf struct {
+		Redis struct {
+			Password string `json:"password"`
+			DB       int    `json:"db"`
+		} `json:"redis"`
+		Mysql struct {
+			DSN string `json:"dsn"`
+		} `json:"mysql"`
+	}
+	if err := c.Get("ceres").Scan(&conf); err != nil {
+		t.Fatal(err)
+	}
+	if conf.Redis.Password != "123456" || conf.Redis.DB != 3 {
+		t.Fatalf("redis = %+v", conf.Redis)
+	}
+	if dsn := conf.Mysql.DSN; dsn != "root:pass@tcp(127.0.0.1:3306)/ceres?charset=utf8mb4,utf8&parseTime=true" {
+		t.Fatalf("dsn = %s", dsn)
+	}
+}
+
+func TestEnvSourceMapping(t *testing.T) {
+	src := NewSource(
+		Mapping(map[string]string{"APP_READ_TIMEOUT": "ceres.app.readTimeout"}),
+		Exclude("CERES_SECRET"),
+		environ(
+			"CERES_APPLICATION_STOP_TIMEOUT=10s",
+			"CERES_APPLICATION_MAX_PROC=4",
+			"APP_READ_TIMEOUT=3s",
+			"CERES_CONFIG_FILE=config.toml",
+			"CERES_CONFIG_KEY=secret",
+			"CERES_PROFILE=prod",
+			"CERES_UPGRADE_READY_FD=5",
+			"CERES_CONFIG_ETCD_WATCH=true",
+			"CERES_SECRET=x",
+			"CERES_CONFIG_SOURCE_HTTP_DEFAULT_URL=http://config",
+		),
+	)
+	c := config.NewConfig()
+	if err := c.LoadSource(src); err != nil {
+		t.Fatal(err)
+	}
+	// 映射的变量保留配置项的大小写
+	if timeout := c.Get("ceres.application.stopTimeout").String(""); timeout != "10s" {
+		t.Fatalf("stopTimeout = %s, want 10s", timeout)
+	}
+	if procs := c.Get("ceres.application.maxProc").Int(0); procs != 4 {
+		t.Fatalf("maxProc = %d, want 4", procs)
+	}
+	if timeout := c.Get("ceres.app.readTimeout").String(""); timeout != "3s" {
+		t.Fatalf("readTimeout = %s, want 3s", timeout)
+	}
+	// 启动时使用的变量不属于配置
+	for _, path := range []string{"ceres.config.file", "ceres.config.key", "ceres.config.etcd", "ceres.profile", "ceres.upgrade", "ceres.secret"} {
+		if !c.Get(path).IsEmpty() {
+			t.Fatalf("%s should be excluded", path)
+		}
+	}
+	if url := c.Get("ceres.config.source.http.default.url").String(""); url != "http://config" {
+		t.Fatalf("url = %s, want http://config", url)
+	}
+}
+
+func TestEnvSourceDoubleUnderscore(t *testing.T) {
+	src := NewSource(Separator("__"), environ("CERES__GORM__MAX_IDLE=10", "CERES_PROFILE=prod"))
+	c := config.NewConfig()
+	if err := c.LoadSource(src); err != nil {
+		t.Fatal(err)
+	}
+	if idle := c.Get("ceres.gorm.max_idle").Int(0); idle != 10 {
+		t.Fatalf("max_idle = %d, want 10", idle)
+	}
+	if !c.Get("ceres_profile").IsEmpty() {
+		t.Fatal("bootstrap variables should be excluded with any separator")
+	}
+}
@@ -0,0 +1,80 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package env
+
+import "strings"
+
+type Option func(e *envSource)
+
+// Prefix 只读取带有这些前缀的环境变量，默认为CERES_，前缀会作为路径的一部分
+func Prefix(prefixes ...string) Option {
+	return func(e *envSource) {
+		e.prefixes = prefixes
+	}
+}
+
+// StripPrefix 只读取带有这些前缀的环境变量，并在映射路径时去掉前缀，
+// 如StripPrefix("APP_")时APP_SERVER_PORT映射为server.port，会取消默认的CERES_前缀
+func StripPrefix(prefixes ...string) Option {
+	return func(e *envSource) {
+		e.stripPrefixes = prefixes
+		e.prefixes = nil
+	}
+}
+
+// Separator 设置环境变量名中路径的分隔符，默认为_，
+// 配置项名称本身含有下划线时可以使用__，如CERES__GORM__MAX_IDLE映射为ceres.gorm.max_idle
+func Separator(sep string) Option {
+	return func(e *envSource) {
+		e.separator = sep
+	}
+}
+
+// KeyMapper 设置路径中每一段的转换方法，默认转为小写
+func KeyMapper(fn func(key string) string) Option {
+	return func(e *envSource) {
+		e.keyMapper = fn
+	}
+}
+
+// Mapping 添加环境变量名到配置路径的映射，映射的变量不受前缀和排除规则限制，
+// 用于含有大写字母的配置项，如CERES_APP_READ_TIMEOUT映射为ceres.app.readTimeout
+func Mapping(mapping map[string]string) Option {
+	return func(e *envSource) {
+		for name, path := range mapping {
+			e.mapping[name] = path
+		}
+	}
+}
+
+// Exclude 添加不读取的环境变量，以_结尾时表示排除该前缀的所有变量
+func Exclude(names ...string) Option {
+	return func(e *envSource) {
+		e.excludes = append(append([]string{}, e.excludes...), names...)
+	}
+}
+
+// Environ 设置读取环境变量的方法，默认为os.Environ
+func Environ(fn func() []string) Option {
+	return func(e *envSource) {
+		e.environ = fn
+	}
+}
+
+// defaultKeyMapper 默认转为小写
+func defaultKeyMapper(key string) string {
+	return strings.ToLower(key)
+}
@@ -9,14 +9,19 @@ import (
 
 type Config struct {
 	*clientv3.Config
-	Prefix     string // etcd配置路径
-	TrimPrefix string // 删除掉的头部字符串
-	Encoding   string // 加解密
+	Prefix     string `usage:"etcd配置路径"`
+	TrimPrefix string `usage:"删除掉的头部字符串"`
+	Encoding   string `usage:"加解密"`
 	Ctx        context.Context
 }
 
 // DefaultConfig 默认的配置信息
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	conf := &Config{
 		Config: &clientv3.Config{
 			Endpoints:   []string{"127.0.0.1:2379"},
@@ -31,8 +36,13 @@ func DefaultConfig() *Config {
 
 // RawConfig 返回配置
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	if err := config.Get(key).Scan(conf); err != nil {
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	if err := config.FromContext(ctx).Get(key).Scan(conf); err != nil {
 		panic(err)
 	}
 	return conf
@@ -40,7 +50,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 返回配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.config.source.etcd." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.config.source.etcd."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.config.source.etcd",
+		Named:       true,
+		Description: "etcd config source",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithEndpoints 连接地址
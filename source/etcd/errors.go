@@ -1 +1,6 @@
 package etcd
+
+import "errors"
+
+// ErrConflict 写入时配置已经被其他客户端修改
+var ErrConflict = errors.New("etcd source: config has been modified since last read")
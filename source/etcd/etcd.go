@@ -4,6 +4,9 @@ import (
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
 	"go.etcd.io/etcd/client/v3"
+	"reflect"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -14,6 +17,8 @@ type etcdSource struct {
 	config  *Config
 	changed chan struct{}
 	err     error
+	mu      sync.Mutex
+	entries []*keyEntry // 最近一次读取到的key
 }
 
 // Read 读取配置
@@ -27,7 +32,10 @@ func (e *etcdSource) Read() (*config.DataSet, error) {
 		return nil, err
 	}
 
-	data := makeMapData(res.Kvs, e.config.TrimPrefix)
+	data, entries := makeMapData(res.Kvs, e.config.TrimPrefix)
+	e.mu.Lock()
+	e.entries = entries
+	e.mu.Unlock()
 	b, err := Marshals[e.config.Encoding](data)
 	if err != nil {
 		return nil, errors.New(500, "error reading source: "+err.Error())
@@ -42,9 +50,69 @@ func (e *etcdSource) Read() (*config.DataSet, error) {
 	return cs, nil
 }
 
-// Write 写入配置
+// Write 按最近一次读取时的key拆分配置后写回，只写入有变化的key，
+// 通过事务校验各key的修改版本，期间被其他客户端修改时返回ErrConflict
 func (e *etcdSource) Write(set *config.DataSet) error {
-	panic("implement me")
+	if e.err != nil {
+		return e.err
+	}
+	fn, ok := Unmarshals[set.Format]
+	if !ok {
+		return errors.New(500, "error writing source: no unmarshal method found for "+set.Format)
+	}
+	data := make(map[string]interface{})
+	if err := fn(set.Data, &data); err != nil {
+		return err
+	}
+	e.mu.Lock()
+	entries := e.entries
+	e.mu.Unlock()
+	values, rest := splitMapData(data, entries)
+	roots := splitRootData(rest, entries)
+	var cmps []clientv3.Cmp
+	var ops []clientv3.Op
+	for _, entry := range entries {
+		value, ok := values[entry]
+		if entry.path == nil {
+			value, ok = roots[entry], true
+		}
+		if !ok {
+			cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(entry.key), "=", entry.revision))
+			ops = append(ops, clientv3.OpDelete(entry.key))
+			continue
+		}
+		if reflect.DeepEqual(normalize(value), entry.value) {
+			continue
+		}
+		b, err := encodeValue(value, entry.format)
+		if err != nil {
+			return err
+		}
+		cmps = append(cmps, clientv3.Compare(clientv3.ModRevision(entry.key), "=", entry.revision))
+		ops = append(ops, clientv3.OpPut(entry.key, string(b)))
+	}
+	// 没有根节点key时，新增的配置写入默认的根节点key
+	if len(roots) == 0 && len(rest) > 0 {
+		key := strings.TrimSuffix(e.config.Prefix, "/") + "/default." + e.getUnmarshal()
+		b, err := encodeValue(rest, e.getUnmarshal())
+		if err != nil {
+			return err
+		}
+		cmps = append(cmps, clientv3.Compare(clientv3.CreateRevision(key), "=", 0))
+		ops = append(ops, clientv3.OpPut(key, string(b)))
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	res, err := e.client.Txn(e.config.Ctx).If(cmps...).Then(ops...).Commit()
+	if err != nil {
+		return err
+	}
+	if !res.Succeeded {
+		return ErrConflict
+	}
+	_, err = e.Read()
+	return err
 }
 
 // IsChanged 配置变化通道
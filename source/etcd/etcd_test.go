@@ -1,30 +1,170 @@
 package etcd
 
 import (
-	"log"
+	"context"
+	"github.com/go-ceres/go-ceres/config"
+	"go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+	"net/url"
+	"os"
+	"strings"
 	"testing"
 	"time"
 )
 
+// TestEtcdSource_Read 连接外部etcd测试读取和监听，需要设置环境变量CERES_TEST_ETCD_ENDPOINTS，多个地址以逗号分隔
 func TestEtcdSource_Read(t *testing.T) {
-	etcd := NewSource(
-		DefaultConfig().WithEndpoints("203.195.200.40:12379", "203.195.200.40:22379", "203.195.200.40:32379"),
-	)
-	ds, err := etcd.Read()
-	etcd.Watch()
-	go func() {
-		for range etcd.IsChanged() {
-			b, err2 := etcd.Read()
-			log.Print(b)
-			log.Print("监测到修改了", "json是：", string(b.Data), "-------", err2)
+	endpoints := os.Getenv("CERES_TEST_ETCD_ENDPOINTS")
+	if endpoints == "" {
+		t.Skip("CERES_TEST_ETCD_ENDPOINTS not set")
+	}
+	conf := DefaultConfig().WithEndpoints(strings.Split(endpoints, ",")...)
+	source := NewSource(conf)
+	if _, err := source.Read(); err != nil {
+		t.Fatal(err)
+	}
+	source.Watch()
+	defer source.UnWatch()
+
+	cli, err := clientv3.New(*conf.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	key := DefaultPrefix + "test/read.json"
+	if _, err := cli.Put(context.Background(), key, `{"value":1}`); err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Delete(context.Background(), key)
+	select {
+	case <-source.IsChanged():
+	case <-time.After(10 * time.Second):
+		t.Fatal("change not notified")
+	}
+	ds, err := source.Read()
+	if err != nil || !strings.Contains(string(ds.Data), `"read":{"value":1}`) {
+		t.Fatalf("Read() = %s, %v", ds.Data, err)
+	}
+}
+
+// startEmbedEtcd 启动测试用的内嵌etcd
+func startEmbedEtcd(t *testing.T) *Config {
+	cfg := embed.NewConfig()
+	cfg.Dir = t.TempDir()
+	cfg.LogLevel = "error"
+	u, _ := url.Parse("http://127.0.0.1:0")
+	cfg.LCUrls = []url.URL{*u}
+	cfg.LPUrls = []url.URL{*u}
+	e, err := embed.StartEtcd(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		e.Close()
+		t.Fatal("embed etcd start timeout")
+	}
+	t.Cleanup(e.Close)
+	return DefaultConfig().WithEndpoints(e.Clients[0].Addr().String())
+}
+
+func TestEtcdSource_Write(t *testing.T) {
+	conf := startEmbedEtcd(t)
+	cli, err := clientv3.New(*conf.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	for k, v := range map[string]string{
+		"/ceres/config/app/server.yaml": "name: demo\nport: 80\n",
+		"/ceres/config/default.json":    `{"log":{"level":"info"}}`,
+		"/ceres/config/feature":         "true",
+	} {
+		if _, err := cli.Put(ctx, k, v); err != nil {
+			t.Fatal(err)
 		}
-		log.Print("结束了")
-	}()
-	time.Sleep(1 * time.Minute)
-	etcd.UnWatch()
-	time.Sleep(1 * time.Minute)
-	etcd.Watch()
-	select {}
+	}
+	c := config.NewConfig()
+	if err := c.LoadSource(NewSource(conf)); err != nil {
+		t.Fatal(err)
+	}
+	if port := c.Get("app.server.port").Int(0); port != 80 {
+		t.Fatalf("app.server.port = %d, want 80", port)
+	}
+	get := func(key string) (string, int64) {
+		res, err := cli.Get(ctx, key)
+		if err != nil || len(res.Kvs) == 0 {
+			t.Fatalf("get %s: %v", key, err)
+		}
+		return string(res.Kvs[0].Value), res.Kvs[0].ModRevision
+	}
+	_, featureRev := get("/ceres/config/feature")
+	_ = c.Set("app.server.port", 8080)
+	_ = c.Set("log.level", "debug")
+	_ = c.Set("extra.key", "v")
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := get("/ceres/config/app/server.yaml"); !strings.Contains(v, "port: 8080") || !strings.Contains(v, "name: demo") {
+		t.Fatalf("app/server.yaml = %s", v)
+	}
+	if v, _ := get("/ceres/config/default.json"); v != `{"extra":{"key":"v"},"log":{"level":"debug"}}` {
+		t.Fatalf("default.json = %s", v)
+	}
+	// 没有变化的key不会重新写入
+	if v, rev := get("/ceres/config/feature"); v != "true" || rev != featureRev {
+		t.Fatalf("feature = %s, revision = %d", v, rev)
+	}
+
+	// 写入前被其他客户端修改时拒绝写入
+	if _, err := cli.Put(ctx, "/ceres/config/app/server.yaml", "port: 90\n"); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set("app.server.port", 9000)
+	if err := c.Write(); err != ErrConflict {
+		t.Fatalf("Write() = %v, want %v", err, ErrConflict)
+	}
+}
 
-	t.Log(string(ds.Data), err)
+func TestEtcdSource_WriteMultipleRoots(t *testing.T) {
+	conf := startEmbedEtcd(t)
+	cli, err := clientv3.New(*conf.Config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cli.Close()
+	ctx := context.Background()
+	for k, v := range map[string]string{
+		"/ceres/config/a.json": `{"app":{"name":"demo"}}`,
+		"/ceres/config/b.json": `{"log":{"level":"info"}}`,
+	} {
+		if _, err := cli.Put(ctx, k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := config.NewConfig()
+	if err := c.LoadSource(NewSource(conf)); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set("log.level", "debug")
+	_ = c.Set("extra", "v")
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+	get := func(key string) string {
+		res, err := cli.Get(ctx, key)
+		if err != nil || len(res.Kvs) == 0 {
+			t.Fatalf("get %s: %v", key, err)
+		}
+		return string(res.Kvs[0].Value)
+	}
+	// 每个根节点key只写回读取时自己的一级key，新增的写入第一个
+	if v := get("/ceres/config/a.json"); v != `{"app":{"name":"demo"},"extra":"v"}` {
+		t.Fatalf("a.json = %s", v)
+	}
+	if v := get("/ceres/config/b.json"); v != `{"log":{"level":"debug"}}` {
+		t.Fatalf("b.json = %s", v)
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"encoding/xml"
+	"fmt"
 	"github.com/BurntSushi/toml"
 	"github.com/go-ceres/go-ceres/logger"
 	"go.etcd.io/etcd/api/v3/mvccpb"
@@ -40,73 +41,235 @@ var (
 type Marshal func(v interface{}) ([]byte, error)
 type Unmarshal func(data []byte, v interface{}) error
 
-// makeMapData 把[]byte根据配置转为map
-func makeMapData(kv []*mvccpb.KeyValue, trimPrefix string) map[string]interface{} {
+// keyEntry 前缀下的单个key，记录其在配置中的位置，用于写回
+type keyEntry struct {
+	key      string      // etcd中的完整key
+	path     []string    // 在配置中的路径，为空时表示整个配置
+	format   string      // 编码格式
+	revision int64       // 读取时的修改版本
+	value    interface{} // 读取时的值
+}
+
+// makeMapData 把前缀下的所有key根据配置合并为map
+func makeMapData(kv []*mvccpb.KeyValue, trimPrefix string) (map[string]interface{}, []*keyEntry) {
 	data := make(map[string]interface{})
+	entries := make([]*keyEntry, 0, len(kv))
 	for _, value := range kv {
-		data = modifyMapData(trimPrefix, data, value)
+		entry := modifyMapData(trimPrefix, data, value)
+		if entry != nil {
+			entries = append(entries, entry)
+		}
 	}
-	return data
+	return data, entries
 }
 
-// 获取解码方法
+// getUnmarshal 获取解码方法，没有可识别的后缀时返回空字符串
 func getUnmarshal(path string) (Unmarshal, string) {
-	UnmarshalStr := strings.TrimPrefix(filepath.Ext(path), ".")
-	if fn, ok := Unmarshals[UnmarshalStr]; ok {
-		return fn, UnmarshalStr
+	str := strings.TrimPrefix(filepath.Ext(path), ".")
+	if fn, ok := Unmarshals[str]; ok {
+		return fn, str
 	}
-	return func(data []byte, v interface{}) error {
-		v = string(data)
-		return nil
-	}, "txt"
+	return nil, ""
 }
 
-// modifyMapData 调整数据
-func modifyMapData(trimPrefix string, data map[string]interface{}, kv *mvccpb.KeyValue) map[string]interface{} {
+// modifyMapData 把单个key的数据合并到配置中，例如：/ceres/config/etcd/default.json合并到etcd.default
+// 只有一级且值为map的key，例如：/ceres/config/default.json，合并到配置根节点
+func modifyMapData(trimPrefix string, data map[string]interface{}, kv *mvccpb.KeyValue) *keyEntry {
 	// 删除前缀，例如：/ceres/config/etcd/default.json,操作后的为：etcd/default.json
 	key := strings.TrimPrefix(strings.TrimPrefix(string(kv.Key), trimPrefix), "/")
 	fn, str := getUnmarshal(key)
-	// 判断是否有后缀,如果没有后缀，默认使用json解码
-	if !strings.HasSuffix(key, "."+str) {
-		fn = Unmarshals["json"]
-	}
 	// 去掉后缀
 	key = strings.TrimSuffix(key, "."+str)
 	// 分割为["etcd","default"]
-	keys := strings.Split(key, "/")
-	// 序列化数据
+	var keys []string
+	for _, k := range strings.Split(key, "/") {
+		if k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+	// 序列化数据，没有后缀时优先使用json解码，失败时作为文本
 	var value interface{}
-	err := fn(kv.Value, &value)
-	if err != nil {
-		logger.Error("解析etcd错误，错误信息为：", err)
-	}
-	if len(keys) > 0 && len(keys) == 1 {
-		v, ok := value.(map[string]interface{})
-		if ok {
-			data = v
-		} else {
-			data = make(map[string]interface{})
-			if str == "txt" {
-				data[keys[0]] = value
+	if fn != nil {
+		if err := fn(kv.Value, &value); err != nil {
+			logger.Error("解析etcd错误，错误信息为：", err)
+			return nil
+		}
+	} else if err := json.Unmarshal(kv.Value, &value); err == nil {
+		str = "json"
+	} else {
+		value, str = string(kv.Value), "txt"
+	}
+	entry := &keyEntry{
+		key:      string(kv.Key),
+		path:     keys,
+		format:   str,
+		revision: kv.ModRevision,
+		value:    normalize(value),
+	}
+	if v, ok := value.(map[string]interface{}); ok && len(keys) == 1 {
+		entry.path = nil
+		mergeMap(data, v)
+		return entry
+	}
+	setValue(data, keys, value)
+	return entry
+}
+
+// splitMapData 按读取时的key拆分配置，返回各key的新值，已经不存在的key不在values中，
+// rest为没有被任何非根节点key包含的配置
+func splitMapData(data map[string]interface{}, entries []*keyEntry) (values map[*keyEntry]interface{}, rest map[string]interface{}) {
+	values = make(map[*keyEntry]interface{}, len(entries))
+	rest = copyMap(data)
+	for _, entry := range entries {
+		if entry.path == nil {
+			continue
+		}
+		if v, ok := getValue(data, entry.path); ok {
+			values[entry] = v
+		}
+		removeValue(rest, entry.path)
+	}
+	return values, rest
+}
+
+// splitRootData 把没有被非根节点key包含的配置按根节点key读取时的一级key拆分，
+// 多个根节点key都有的一级key写入每个根节点key，新增的一级key写入第一个根节点key
+func splitRootData(rest map[string]interface{}, entries []*keyEntry) map[*keyEntry]map[string]interface{} {
+	roots := make(map[*keyEntry]map[string]interface{})
+	var first *keyEntry
+	owned := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.path != nil {
+			continue
+		}
+		if first == nil {
+			first = entry
+		}
+		value := make(map[string]interface{})
+		old, _ := entry.value.(map[string]interface{})
+		for k := range old {
+			if v, ok := rest[k]; ok {
+				value[k] = v
 			}
+			owned[k] = true
+		}
+		roots[entry] = value
+	}
+	if first == nil {
+		return roots
+	}
+	for k, v := range rest {
+		if !owned[k] {
+			roots[first][k] = v
 		}
-		return data
 	}
+	return roots
+}
 
-	tempData := data
-	for i, k := range keys {
-		// 先判断该key是否已经存在值
-		kData, ok := data[k].(map[string]interface{})
+// setValue 根据路径设置值
+func setValue(data map[string]interface{}, keys []string, value interface{}) {
+	cur := data
+	for _, k := range keys[:len(keys)-1] {
+		next, ok := cur[k].(map[string]interface{})
 		if !ok {
-			kData = make(map[string]interface{})
-			tempData[k] = kData
+			next = make(map[string]interface{})
+			cur[k] = next
 		}
-		// 如果是最后一个key，则设置数据
-		if len(keys)-1 == i {
-			tempData[k] = value
-		} else {
-			tempData = kData
+		cur = next
+	}
+	last := keys[len(keys)-1]
+	if src, ok := value.(map[string]interface{}); ok {
+		if dst, ok := cur[last].(map[string]interface{}); ok {
+			mergeMap(dst, src)
+			return
 		}
 	}
-	return data
+	cur[last] = value
+}
+
+// getValue 根据路径获取值
+func getValue(data map[string]interface{}, keys []string) (interface{}, bool) {
+	var cur interface{} = data
+	for _, k := range keys {
+		m, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = m[k]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// removeValue 删除路径对应的值，并清理删除后为空的上级map
+func removeValue(data map[string]interface{}, keys []string) {
+	if len(keys) == 1 {
+		delete(data, keys[0])
+		return
+	}
+	m, ok := data[keys[0]].(map[string]interface{})
+	if !ok {
+		return
+	}
+	removeValue(m, keys[1:])
+	if len(m) == 0 {
+		delete(data, keys[0])
+	}
+}
+
+// mergeMap 将src深度合并到dst
+func mergeMap(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		dstMap, ok2 := dst[k].(map[string]interface{})
+		if ok && ok2 {
+			mergeMap(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// copyMap 深度复制map
+func copyMap(src map[string]interface{}) map[string]interface{} {
+	dst := make(map[string]interface{}, len(src))
+	for k, v := range src {
+		if m, ok := v.(map[string]interface{}); ok {
+			v = copyMap(m)
+		}
+		dst[k] = v
+	}
+	return dst
+}
+
+// encodeValue 按key的编码格式编码值
+func encodeValue(value interface{}, format string) ([]byte, error) {
+	if format == "txt" {
+		if s, ok := value.(string); ok {
+			return []byte(s), nil
+		}
+		format = "json"
+	}
+	fn, ok := Marshals[format]
+	if !ok {
+		return nil, fmt.Errorf("no marshal method found for %s", format)
+	}
+	return fn(value)
+}
+
+// normalize 通过json转换统一数据类型，便于比较是否有变化
+func normalize(value interface{}) interface{} {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return value
+	}
+	return v
 }
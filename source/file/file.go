@@ -16,27 +16,42 @@
 package file
 
 import (
+	"encoding/json"
+	"fmt"
 	"github.com/fsnotify/fsnotify"
 	"github.com/go-ceres/go-ceres/config"
 	"io/ioutil"
 	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
+	"time"
 )
 
 type fileSource struct {
 	path      string
 	dir       string
+	isDir     bool   // path是否为目录
+	profile   string // 环境名称，设置后读取对应环境的配置文件
 	unmarshal string
 	watcher   *fsnotify.Watcher
 	changed   chan struct{}
+	done      chan struct{} // 监听结束
 }
 
 func (fs *fileSource) Read() (*config.DataSet, error) {
-	fh, err := os.Open(fs.path)
+	if fs.isDir {
+		return fs.readDir()
+	}
+	path := fs.file()
+	fh, err := os.Open(path)
 	if err != nil {
+		// 环境配置文件是可选的
+		if fs.profile != "" && os.IsNotExist(err) {
+			return fs.empty(), nil
+		}
 		return nil, err
 	}
 	defer func() {
@@ -61,8 +76,87 @@ func (fs *fileSource) Read() (*config.DataSet, error) {
 	return cs, nil
 }
 
+// readDir 按文件名顺序读取目录下所有支持的配置文件并合并，后读取的优先
+func (fs *fileSource) readDir() (*config.DataSet, error) {
+	files, err := fs.files()
+	if err != nil {
+		return nil, err
+	}
+	data := make(map[string]interface{})
+	var modTime time.Time
+	for _, file := range files {
+		b, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		item := make(map[string]interface{})
+		if err := config.Unmarshals[format(file)](b, &item); err != nil {
+			return nil, fmt.Errorf("read file %s: %w", file, err)
+		}
+		merge(data, item)
+		if info, err := os.Stat(file); err == nil && info.ModTime().After(modTime) {
+			modTime = info.ModTime()
+		}
+	}
+	b, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	cs := &config.DataSet{
+		Format:    fs.getUnmarshal(),
+		Source:    fs.String(),
+		Timestamp: modTime,
+		Data:      b,
+	}
+	cs.Checksum = cs.Sum()
+	return cs, nil
+}
+
+// empty 环境配置文件不存在时的空配置
+func (fs *fileSource) empty() *config.DataSet {
+	cs := &config.DataSet{
+		Format:    "json",
+		Source:    fs.String(),
+		Timestamp: time.Now(),
+		Data:      []byte("{}"),
+	}
+	cs.Checksum = cs.Sum()
+	return cs
+}
+
+// Write 按文件原有格式写回配置，先写入同目录下的临时文件再原子替换，目录由多个文件合并而成，不支持写入
 func (fs *fileSource) Write(dataSet *config.DataSet) error {
-	return nil
+	if fs.isDir {
+		return config.ErrReadOnly
+	}
+	path := fs.file()
+	if dataSet.Format != fs.getUnmarshal() {
+		return fmt.Errorf("write file %s: format %s mismatch %s", path, dataSet.Format, fs.getUnmarshal())
+	}
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode()
+	}
+	tmp, err := ioutil.TempFile(fs.dir, "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = os.Remove(tmp.Name())
+	}()
+	if _, err = tmp.Write(dataSet.Data); err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
 func (fs *fileSource) IsChanged() <-chan struct{} {
@@ -78,29 +172,40 @@ func (fs *fileSource) Watch() {
 			log.Fatal("new watch", err)
 		}
 	}
+	watcher, changed := fs.watcher, fs.changed
+	fs.done = make(chan struct{})
+	done := fs.done
 	initWG := sync.WaitGroup{}
 	initWG.Add(1)
 	go func() {
 		eventsWG := sync.WaitGroup{}
 		eventsWG.Add(1)
 		go func() {
+			defer eventsWG.Done()
+			defer close(done)
 			for {
 				select {
-				case event := <-fs.watcher.Events:
-					const writeOrCreateMask = fsnotify.Write | fsnotify.Create
-					if event.Op&writeOrCreateMask != 0 && filepath.Clean(event.Name) == filepath.Clean(fs.path) {
-						log.Println("modified file: ", event.Name)
-						select {
-						case fs.changed <- struct{}{}:
-						default:
-						}
+				case event, ok := <-watcher.Events:
+					if !ok {
+						return
+					}
+					if event.Op == fsnotify.Chmod || !fs.watched(event.Name) {
+						continue
+					}
+					log.Println("modified file: ", event.Name)
+					select {
+					case changed <- struct{}{}:
+					default:
+					}
+				case err, ok := <-watcher.Errors:
+					if !ok {
+						return
 					}
-				case err := <-fs.watcher.Errors:
 					log.Printf("watcher error: %v\n", err)
 				}
 			}
 		}()
-		err := fs.watcher.Add(fs.dir)
+		err := watcher.Add(fs.dir)
 		if err != nil {
 			log.Fatal("add file to fsnotify error:", err)
 		}
@@ -110,18 +215,40 @@ func (fs *fileSource) Watch() {
 	initWG.Wait()
 }
 
+// watched 判断文件变化是否需要重新读取，Kubernetes挂载的ConfigMap通过替换..data软链接更新，
+// 软链接指向的文件本身不会产生事件，所以..开头的文件变化也需要重新读取
+func (fs *fileSource) watched(name string) bool {
+	base := filepath.Base(name)
+	if strings.HasPrefix(base, "..") {
+		return true
+	}
+	if fs.isDir {
+		_, ok := config.Unmarshals[format(name)]
+		return ok && !strings.HasPrefix(base, ".")
+	}
+	return filepath.Clean(name) == filepath.Clean(fs.file())
+}
+
+// UnWatch 取消监听，等待事件处理结束后再关闭变化通道
 func (fs *fileSource) UnWatch() {
+	_ = fs.watcher.Close()
+	<-fs.done
 	close(fs.changed)
 	fs.changed = nil
-	_ = fs.watcher.Close()
 	fs.watcher = nil
 }
 
 func (fs *fileSource) String() string {
+	if fs.profile != "" {
+		return "file:" + fs.profile
+	}
 	return "file"
 }
 
 func (fs *fileSource) getUnmarshal() string {
+	if fs.isDir {
+		return "json"
+	}
 	parts := strings.Split(fs.path, ".")
 	if len(parts) > 1 {
 		return parts[len(parts)-1]
@@ -129,6 +256,68 @@ func (fs *fileSource) getUnmarshal() string {
 	return fs.unmarshal
 }
 
+// file 获取需要读取的配置文件，设置环境时为同目录下的config.<profile>.toml形式的文件
+func (fs *fileSource) file() string {
+	if fs.profile == "" {
+		return fs.path
+	}
+	ext := filepath.Ext(fs.path)
+	return strings.TrimSuffix(fs.path, ext) + "." + fs.profile + ext
+}
+
+// files 获取目录下需要读取的配置文件，按文件名排序，
+// 文件名中带有环境的配置文件，例如config.dev.toml，只有设置了对应的环境时才会读取
+func (fs *fileSource) files() ([]string, error) {
+	entries, err := ioutil.ReadDir(fs.path)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") {
+			continue
+		}
+		if _, ok := config.Unmarshals[format(name)]; !ok {
+			continue
+		}
+		stem := strings.TrimSuffix(name, filepath.Ext(name))
+		if fs.profile == "" && strings.Contains(stem, ".") {
+			continue
+		}
+		if fs.profile != "" && !strings.HasSuffix(stem, "."+fs.profile) {
+			continue
+		}
+		path := filepath.Join(fs.path, name)
+		// 跟随软链接判断是否为文件
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// format 根据文件后缀获取格式
+func format(name string) string {
+	return strings.TrimPrefix(filepath.Ext(name), ".")
+}
+
+// merge 将src深度合并到dst
+func merge(dst, src map[string]interface{}) {
+	for k, v := range src {
+		srcMap, ok := v.(map[string]interface{})
+		dstMap, ok2 := dst[k].(map[string]interface{})
+		if ok && ok2 {
+			merge(dstMap, srcMap)
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// NewSource 创建文件配置源，file为目录时读取目录下所有支持的配置文件
 func NewSource(file string, opts ...Option) config.Source {
 	file, err := filepath.Abs(file)
 	if err != nil {
@@ -138,6 +327,10 @@ func NewSource(file string, opts ...Option) config.Source {
 		path: file,
 		dir:  filepath.Dir(file),
 	}
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		eng.isDir = true
+		eng.dir = file
+	}
 	for _, o := range opts {
 		o(&eng)
 	}
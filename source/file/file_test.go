@@ -0,0 +1,170 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package file
+
+import (
+	"github.com/go-ceres/go-ceres/config"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFileSource_Write(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	if err := ioutil.WriteFile(path, []byte("[app]\nname = \"demo\"\nport = 80\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	c := config.NewConfig()
+	if err := c.LoadSource(NewSource(path)); err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set("app.port", 8080)
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "port = 8080\n") || !strings.Contains(string(b), "name = \"demo\"") {
+		t.Fatalf("unexpected content: %s", b)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Fatalf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	// 临时文件已经被替换
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Fatalf("files = %d, want 1", len(files))
+	}
+
+	c = config.NewConfig()
+	if err := c.LoadSource(NewSource(path)); err != nil {
+		t.Fatal(err)
+	}
+	if port := c.Get("app.port").Int(0); port != 8080 {
+		t.Fatalf("app.port = %d, want 8080", port)
+	}
+	if err := NewSource(path).Write(&config.DataSet{Format: "json"}); err == nil {
+		t.Fatal("format mismatch should fail")
+	}
+}
+
+func TestFileSource_Dir(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"a.toml":          "[app]\nname = \"a\"\nport = 80\n",
+		"b.yaml":          "app:\n  name: b\n",
+		"c.json":          `{"redis":{"addrs":["127.0.0.1"]}}`,
+		"config.dev.toml": "[app]\nport = 8080\n",
+		".hidden.json":    `{"app":{"name":"hidden"}}`,
+		"README.md":       "readme",
+	} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c := config.NewConfig()
+	if err := c.LoadSource(NewSource(dir)); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.LoadSource(NewSource(dir, Profile("dev"))); err != nil {
+		t.Fatal(err)
+	}
+	// 环境配置不存在时为空配置
+	if err := c.LoadSource(NewSource(dir, Profile("prod"))); err != nil {
+		t.Fatal(err)
+	}
+	if name := c.Get("app.name").String(""); name != "b" {
+		t.Fatalf("app.name = %s, want b", name)
+	}
+	if port := c.Get("app.port").Int(0); port != 8080 {
+		t.Fatalf("app.port = %d, want 8080", port)
+	}
+	if got := c.SourceOf("app.port"); got != "file:dev" {
+		t.Fatalf("SourceOf(app.port) = %s, want file:dev", got)
+	}
+	if addrs := c.Get("redis.addrs").StringSlice(nil); len(addrs) != 1 {
+		t.Fatalf("redis.addrs = %v", addrs)
+	}
+	if err := NewSource(dir).Write(&config.DataSet{Format: "json"}); err != config.ErrReadOnly {
+		t.Fatalf("Write() = %v, want %v", err, config.ErrReadOnly)
+	}
+}
+
+func TestFileSource_Profile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.toml")
+	_ = ioutil.WriteFile(path, []byte("[app]\nname = \"demo\"\nport = 80\n"), 0644)
+	_ = ioutil.WriteFile(filepath.Join(dir, "config.test.toml"), []byte("[app]\nport = 81\n"), 0644)
+	c := config.NewConfig()
+	_ = c.LoadSource(NewSource(path))
+	if err := c.LoadSource(NewSource(path, Profile("test"))); err != nil {
+		t.Fatal(err)
+	}
+	if port := c.Get("app.port").Int(0); port != 81 {
+		t.Fatalf("app.port = %d, want 81", port)
+	}
+	if name := c.Get("app.name").String(""); name != "demo" {
+		t.Fatalf("app.name = %s, want demo", name)
+	}
+}
+
+func TestFileSource_WatchConfigMap(t *testing.T) {
+	// 模拟Kubernetes挂载ConfigMap的目录结构：config.toml -> ..data/config.toml，..data -> ..v1
+	dir := t.TempDir()
+	writeVersion := func(version, content string) {
+		_ = os.Mkdir(filepath.Join(dir, version), 0755)
+		_ = ioutil.WriteFile(filepath.Join(dir, version, "config.toml"), []byte(content), 0644)
+		_ = os.Symlink(version, filepath.Join(dir, "..data_tmp"))
+		_ = os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data"))
+	}
+	writeVersion("..v1", "[app]\nport = 80\n")
+	path := filepath.Join(dir, "config.toml")
+	if err := os.Symlink(filepath.Join("..data", "config.toml"), path); err != nil {
+		t.Fatal(err)
+	}
+	c := config.NewConfig()
+	if err := c.LoadSource(NewSource(path)); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan config.Values, 1)
+	c.OnChange(func(v config.Values) {
+		select {
+		case changed <- v:
+		default:
+		}
+	})
+	c.Watch()
+	defer c.UnWatch()
+
+	writeVersion("..v2", "[app]\nport = 81\n")
+	select {
+	case v := <-changed:
+		if port := v.Get("app.port").Int(0); port != 81 {
+			t.Fatalf("app.port = %d, want 81", port)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("configmap swap not detected")
+	}
+}
@@ -7,11 +7,20 @@ import (
 
 type Option func(f *fileSource)
 
+// Unmarshal 设置没有后缀的配置文件使用的解码方式
 func Unmarshal(unmarshal string) Option {
 	return func(f *fileSource) {
-		if _, ok := config.Unmarshals[unmarshal]; ok {
+		if _, ok := config.Unmarshals[unmarshal]; !ok {
 			logger.FrameLogger.Panicd("set unmarshal err: no unmarshal name")
 		}
 		f.unmarshal = unmarshal
 	}
 }
+
+// Profile 设置环境，读取环境对应的配置文件，例如config.toml对应的config.dev.toml，
+// 目录则读取目录下所有以.dev为后缀的配置文件，文件不存在时为空配置
+func Profile(profile string) Option {
+	return func(f *fileSource) {
+		f.profile = profile
+	}
+}
@@ -0,0 +1,247 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ceres/go-ceres/config"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// contentTypes Content-Type对应的配置格式
+var contentTypes = map[string]string{
+	"application/json":   "json",
+	"text/json":          "json",
+	"application/yaml":   "yaml",
+	"application/x-yaml": "yaml",
+	"text/yaml":          "yaml",
+	"text/x-yaml":        "yaml",
+	"application/toml":   "toml",
+	"text/toml":          "toml",
+	"application/xml":    "xml",
+	"text/xml":           "xml",
+}
+
+type httpSource struct {
+	url        string
+	format     string
+	header     http.Header
+	client     *http.Client
+	interval   time.Duration
+	wait       time.Duration
+	minBackoff time.Duration
+	maxBackoff time.Duration
+
+	mu      sync.Mutex
+	etag    string
+	dataSet *config.DataSet // 最近一次获取到的配置
+	changed chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+// Read 读取配置，已经获取过配置时带上If-None-Match，内容没有变化时直接返回上次的配置
+func (h *httpSource) Read() (*config.DataSet, error) {
+	dataSet, _, err := h.fetch(context.Background(), 0)
+	return dataSet, err
+}
+
+// Write http配置源不支持写入
+func (h *httpSource) Write(*config.DataSet) error {
+	return config.ErrReadOnly
+}
+
+// IsChanged 配置变化通道，只有内容的校验和变化时才会通知
+func (h *httpSource) IsChanged() <-chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.changed
+}
+
+// Watch 开启轮询
+func (h *httpSource) Watch() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.cancel != nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.cancel = cancel
+	h.changed = make(chan struct{}, 1)
+	h.done = make(chan struct{})
+	go h.poll(ctx, h.changed, h.done)
+}
+
+// UnWatch 停止轮询
+func (h *httpSource) UnWatch() {
+	h.mu.Lock()
+	cancel, done, changed := h.cancel, h.done, h.changed
+	h.cancel, h.done, h.changed = nil, nil, nil
+	h.mu.Unlock()
+	if cancel == nil {
+		return
+	}
+	cancel()
+	<-done
+	close(changed)
+}
+
+// String 配置源名称
+func (h *httpSource) String() string {
+	return "http"
+}
+
+// poll 轮询配置，请求失败时按退避时间重试
+func (h *httpSource) poll(ctx context.Context, changed chan struct{}, done chan struct{}) {
+	defer close(done)
+	backoff := h.minBackoff
+	for {
+		_, modified, err := h.fetch(ctx, h.wait)
+		delay := h.interval
+		if h.wait > 0 {
+			delay = 0
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			delay = backoff
+			if backoff *= 2; backoff > h.maxBackoff {
+				backoff = h.maxBackoff
+			}
+		} else {
+			backoff = h.minBackoff
+		}
+		if modified {
+			select {
+			case changed <- struct{}{}:
+			default:
+			}
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		} else if ctx.Err() != nil {
+			return
+		}
+	}
+}
+
+// fetch 请求配置，modified表示配置内容的校验和是否变化
+func (h *httpSource) fetch(ctx context.Context, wait time.Duration) (*config.DataSet, bool, error) {
+	h.mu.Lock()
+	etag, last := h.etag, h.dataSet
+	h.mu.Unlock()
+	if wait > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, wait+10*time.Second)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, v := range h.header {
+		req.Header[k] = v
+	}
+	if etag != "" && last != nil {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if wait > 0 {
+		req.Header.Set("Prefer", "wait="+strconv.Itoa(int(wait/time.Second)))
+	}
+	res, err := h.client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode == http.StatusNotModified && last != nil {
+		return last, false, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("fetch config %s: unexpected status %s", h.url, res.Status)
+	}
+	b, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	dataSet := &config.DataSet{
+		Data:      b,
+		Format:    h.getFormat(res.Header.Get("Content-Type")),
+		Source:    h.String(),
+		Timestamp: time.Now(),
+	}
+	dataSet.Checksum = dataSet.Sum()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	modified := h.dataSet == nil || h.dataSet.Checksum != dataSet.Checksum
+	h.etag = res.Header.Get("ETag")
+	if modified {
+		h.dataSet = dataSet
+	}
+	return h.dataSet, modified, nil
+}
+
+// getFormat 获取配置格式
+func (h *httpSource) getFormat(contentType string) string {
+	if h.format != "" {
+		return h.format
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		if format, ok := contentTypes[mediaType]; ok {
+			return format
+		}
+	}
+	if u, err := url.Parse(h.url); err == nil {
+		ext := strings.TrimPrefix(path.Ext(u.Path), ".")
+		if _, ok := config.Unmarshals[ext]; ok {
+			return ext
+		}
+	}
+	return "json"
+}
+
+// NewSource 创建http配置源
+func NewSource(rawURL string, opts ...Option) config.Source {
+	h := &httpSource{
+		url:        rawURL,
+		header:     make(http.Header),
+		client:     http.DefaultClient,
+		interval:   10 * time.Second,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
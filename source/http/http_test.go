@@ -0,0 +1,178 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"fmt"
+	"github.com/go-ceres/go-ceres/config"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// configServer 测试用的配置服务
+type configServer struct {
+	mu          sync.Mutex
+	body        string
+	contentType string
+	version     int
+	status      int
+	requests    int32
+}
+
+func (s *configServer) set(body string, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body, s.status = body, status
+	s.version++
+}
+
+func (s *configServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&s.requests, 1)
+	s.mu.Lock()
+	body, status, etag := s.body, s.status, strconv.Quote(strconv.Itoa(s.version))
+	s.mu.Unlock()
+	if status != http.StatusOK {
+		w.WriteHeader(status)
+		return
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", s.contentType)
+	_, _ = fmt.Fprint(w, body)
+}
+
+func TestHttpSource_Read(t *testing.T) {
+	srv := &configServer{body: "app:\n  port: 80\n", contentType: "application/x-yaml; charset=utf-8", status: http.StatusOK}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	source := NewSource(ts.URL + "/config")
+	ds, err := source.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Format != "yaml" || ds.Checksum == "" {
+		t.Fatalf("dataSet = %+v", ds)
+	}
+	// 内容没有变化时返回上次的配置
+	cached, err := source.Read()
+	if err != nil || cached != ds {
+		t.Fatalf("Read() = %v, %v", cached, err)
+	}
+	if ds, _ := NewSource(ts.URL+"/config", Format("json")).Read(); ds.Format != "json" {
+		t.Fatalf("format = %s, want json", ds.Format)
+	}
+	srv.contentType = "text/plain"
+	if ds, _ := NewSource(ts.URL + "/config.toml?v=1").Read(); ds.Format != "toml" {
+		t.Fatalf("format = %s, want toml", ds.Format)
+	}
+}
+
+func TestHttpSource_Watch(t *testing.T) {
+	srv := &configServer{body: `{"app":{"port":80}}`, contentType: "application/json", status: http.StatusOK}
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	c := config.NewConfig()
+	source := NewSource(ts.URL, Interval(10*time.Millisecond), Backoff(100*time.Millisecond, 200*time.Millisecond))
+	if err := c.LoadSource(source); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan config.Values, 1)
+	c.OnChange(func(v config.Values) {
+		changed <- v
+	})
+	c.Watch()
+	defer c.UnWatch()
+
+	// 版本变化但内容相同时不通知
+	srv.set(`{"app":{"port":80}}`, http.StatusOK)
+	select {
+	case <-changed:
+		t.Fatal("same content should not notify")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// 服务端失败时退避
+	srv.set("", http.StatusInternalServerError)
+	time.Sleep(50 * time.Millisecond)
+	before := atomic.LoadInt32(&srv.requests)
+	time.Sleep(350 * time.Millisecond)
+	if n := atomic.LoadInt32(&srv.requests) - before; n > 4 {
+		t.Fatalf("requests during failure = %d, want backoff", n)
+	}
+
+	srv.set(`{"app":{"port":81}}`, http.StatusOK)
+	select {
+	case v := <-changed:
+		if port := v.Get("app.port").Int(0); port != 81 {
+			t.Fatalf("app.port = %d, want 81", port)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("change not notified")
+	}
+}
+
+func TestHttpSource_LongPoll(t *testing.T) {
+	var version int32
+	update := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		etag := strconv.Quote(strconv.Itoa(int(atomic.LoadInt32(&version))))
+		if r.Header.Get("If-None-Match") == etag {
+			// 非长轮询的条件请求直接返回
+			if r.Header.Get("Prefer") != "wait=1" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			// 等待配置变化或超时
+			select {
+			case <-update:
+			case <-time.After(time.Second):
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			etag = strconv.Quote(strconv.Itoa(int(atomic.AddInt32(&version, 1))))
+		}
+		w.Header().Set("ETag", etag)
+		_, _ = fmt.Fprintf(w, `{"version":%d}`, atomic.LoadInt32(&version))
+	}))
+	defer ts.Close()
+
+	source := NewSource(ts.URL, LongPoll(time.Second))
+	if _, err := source.Read(); err != nil {
+		t.Fatal(err)
+	}
+	source.Watch()
+	defer source.UnWatch()
+	update <- struct{}{}
+	select {
+	case <-source.IsChanged():
+	case <-time.After(2 * time.Second):
+		t.Fatal("long poll change not notified")
+	}
+	ds, _ := source.Read()
+	if string(ds.Data) != `{"version":1}` {
+		t.Fatalf("data = %s", ds.Data)
+	}
+}
@@ -0,0 +1,67 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package http
+
+import (
+	"net/http"
+	"time"
+)
+
+type Option func(h *httpSource)
+
+// Format 设置配置格式，默认根据响应的Content-Type推断，无法推断时根据url后缀，都没有时为json
+func Format(format string) Option {
+	return func(h *httpSource) {
+		h.format = format
+	}
+}
+
+// Header 设置请求头，例如认证信息
+func Header(key, value string) Option {
+	return func(h *httpSource) {
+		h.header.Set(key, value)
+	}
+}
+
+// Client 设置http客户端，默认为http.DefaultClient
+func Client(client *http.Client) Option {
+	return func(h *httpSource) {
+		h.client = client
+	}
+}
+
+// Interval 设置轮询间隔，默认为10s
+func Interval(interval time.Duration) Option {
+	return func(h *httpSource) {
+		h.interval = interval
+	}
+}
+
+// LongPoll 使用长轮询，请求时通过Prefer: wait=<秒数>请求头告知服务端最多等待的时间，
+// 服务端在配置变化或超时后响应，收到响应后立即发起下一次请求
+func LongPoll(wait time.Duration) Option {
+	return func(h *httpSource) {
+		h.wait = wait
+	}
+}
+
+// Backoff 设置请求失败后重试的退避时间范围，默认为1s到1m，每次失败后翻倍
+func Backoff(min, max time.Duration) Option {
+	return func(h *httpSource) {
+		h.minBackoff = min
+		h.maxBackoff = max
+	}
+}
@@ -0,0 +1,265 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package nacos
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/cache"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/http_agent"
+	sdklogger "github.com/nacos-group/nacos-sdk-go/v2/common/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_error"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/nacos_server"
+	"github.com/nacos-group/nacos-sdk-go/v2/model"
+	"github.com/nacos-group/nacos-sdk-go/v2/util"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// pollTimeout 监听配置时长轮询的超时时间
+	pollTimeout = 30 * time.Second
+	// retryInterval 监听请求失败后重试的间隔
+	retryInterval = time.Second
+)
+
+// httpClient 通过nacos开放接口读写和监听配置，1.x和2.x的服务端都支持该接口，
+// 请求地址、鉴权和重试由sdk的NacosServer处理
+type httpClient struct {
+	server    *nacos_server.NacosServer
+	namespace string
+	timeoutMs uint64
+	cacheDir  string
+	ctx       context.Context
+	cancel    context.CancelFunc
+	mu        sync.Mutex
+	listeners map[string]context.CancelFunc
+}
+
+// newHTTPClient 创建nacos开放接口客户端
+func newHTTPClient(servers []constant.ServerConfig, clientConfig constant.ClientConfig) (*httpClient, error) {
+	// 与sdk的客户端一致，日志写入LogDir
+	if err := sdklogger.InitLogger(sdklogger.BuildLoggerConfig(clientConfig)); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	server, err := nacos_server.NewNacosServer(ctx, servers, clientConfig, &http_agent.HttpAgent{}, clientConfig.TimeoutMs, clientConfig.Endpoint)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &httpClient{
+		server:    server,
+		namespace: clientConfig.NamespaceId,
+		timeoutMs: clientConfig.TimeoutMs,
+		cacheDir:  clientConfig.CacheDir + string(os.PathSeparator) + "config",
+		ctx:       ctx,
+		cancel:    cancel,
+		listeners: make(map[string]context.CancelFunc),
+	}, nil
+}
+
+// GetConfig 读取配置，配置不存在时返回空字符串，服务端不可用时读取本地缓存
+func (c *httpClient) GetConfig(param vo.ConfigParam) (string, error) {
+	key := util.GetConfigCacheKey(param.DataId, param.Group, c.namespace)
+	content, err := c.server.ReqConfigApi(constant.CONFIG_PATH, escape(map[string]string{
+		"dataId": param.DataId,
+		"group":  param.Group,
+		"tenant": c.namespace,
+	}), nil, http.MethodGet, c.timeoutMs)
+	if err != nil {
+		if isNotFound(err) {
+			cache.WriteConfigToFile(key, c.cacheDir, "")
+			return "", nil
+		}
+		if cached, cacheErr := cache.ReadConfigFromFile(key, c.cacheDir); cacheErr == nil {
+			return cached, nil
+		}
+		return "", err
+	}
+	cache.WriteConfigToFile(key, c.cacheDir, content)
+	return content, nil
+}
+
+// PublishConfig 发布配置
+func (c *httpClient) PublishConfig(param vo.ConfigParam) (bool, error) {
+	if param.Content == "" {
+		return false, errors.New("nacos: content can not be empty")
+	}
+	params := map[string]string{
+		"dataId":  param.DataId,
+		"group":   param.Group,
+		"tenant":  c.namespace,
+		"content": param.Content,
+	}
+	if param.Type != "" {
+		params["type"] = param.Type
+	}
+	if param.CasMd5 != "" {
+		params["casMd5"] = param.CasMd5
+	}
+	result, err := c.server.ReqConfigApi(constant.CONFIG_PATH, params, nil, http.MethodPost, c.timeoutMs)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(result) == "true", nil
+}
+
+// DeleteConfig 删除配置
+func (c *httpClient) DeleteConfig(param vo.ConfigParam) (bool, error) {
+	result, err := c.server.ReqConfigApi(constant.CONFIG_PATH, escape(map[string]string{
+		"dataId": param.DataId,
+		"group":  param.Group,
+		"tenant": c.namespace,
+	}), nil, http.MethodDelete, c.timeoutMs)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(result) == "true", nil
+}
+
+// ListenConfig 以当前内容为基准长轮询监听配置，变化时回调OnChange，读取当前内容失败时返回错误
+func (c *httpClient) ListenConfig(param vo.ConfigParam) error {
+	if param.OnChange == nil {
+		return errors.New("nacos: OnChange can not be nil")
+	}
+	content, err := c.GetConfig(param)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(c.ctx)
+	key := util.GetConfigCacheKey(param.DataId, param.Group, c.namespace)
+	c.mu.Lock()
+	if old, ok := c.listeners[key]; ok {
+		old()
+	}
+	c.listeners[key] = cancel
+	c.mu.Unlock()
+	go c.listen(ctx, param, util.Md5(content))
+	return nil
+}
+
+// CancelListenConfig 取消监听配置，进行中的长轮询返回后退出
+func (c *httpClient) CancelListenConfig(param vo.ConfigParam) error {
+	key := util.GetConfigCacheKey(param.DataId, param.Group, c.namespace)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cancel, ok := c.listeners[key]; ok {
+		cancel()
+		delete(c.listeners, key)
+	}
+	return nil
+}
+
+// SearchConfig 搜索配置
+func (c *httpClient) SearchConfig(param vo.SearchConfigParam) (*model.ConfigPage, error) {
+	if param.PageNo <= 0 {
+		param.PageNo = 1
+	}
+	if param.PageSize <= 0 {
+		param.PageSize = 10
+	}
+	result, err := c.server.ReqConfigApi(constant.CONFIG_PATH, escape(map[string]string{
+		"search":   param.Search,
+		"dataId":   param.DataId,
+		"group":    param.Group,
+		"tenant":   c.namespace,
+		"pageNo":   strconv.Itoa(param.PageNo),
+		"pageSize": strconv.Itoa(param.PageSize),
+	}), nil, http.MethodGet, c.timeoutMs)
+	if err != nil {
+		return nil, err
+	}
+	page := &model.ConfigPage{}
+	if err := json.Unmarshal([]byte(result), page); err != nil {
+		return nil, fmt.Errorf("nacos: decode search result: %w", err)
+	}
+	return page, nil
+}
+
+// CloseClient 关闭客户端，停止所有监听
+func (c *httpClient) CloseClient() {
+	c.cancel()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = make(map[string]context.CancelFunc)
+}
+
+// listen 长轮询监听配置，请求失败时间隔一段时间后重试
+func (c *httpClient) listen(ctx context.Context, param vo.ConfigParam, md5 string) {
+	for ctx.Err() == nil {
+		changed, err := c.poll(param, md5)
+		if ctx.Err() != nil {
+			return
+		}
+		var content string
+		if err == nil && changed {
+			content, err = c.GetConfig(param)
+		}
+		if err != nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(retryInterval):
+			}
+			continue
+		}
+		if changed && util.Md5(content) != md5 {
+			md5 = util.Md5(content)
+			param.OnChange(c.namespace, param.Group, param.DataId, content)
+		}
+	}
+}
+
+// poll 发起一次长轮询，服务端在配置的md5变化或超时后返回，返回内容不为空表示配置变化
+func (c *httpClient) poll(param vo.ConfigParam, md5 string) (bool, error) {
+	listening := param.DataId + constant.SPLIT_CONFIG_INNER + param.Group + constant.SPLIT_CONFIG_INNER + md5
+	if c.namespace != "" {
+		listening += constant.SPLIT_CONFIG_INNER + c.namespace
+	}
+	headers := map[string]string{
+		"Long-Pulling-Timeout": strconv.FormatInt(pollTimeout.Milliseconds(), 10),
+	}
+	result, err := c.server.ReqConfigApi(constant.CONFIG_LISTEN_PATH, map[string]string{
+		"Listening-Configs": listening + constant.SPLIT_CONFIG,
+	}, headers, http.MethodPost, uint64(pollTimeout.Milliseconds())+c.timeoutMs)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(result) != "", nil
+}
+
+// escape 编码查询参数，sdk拼接GET和DELETE请求的地址时不做编码
+func escape(params map[string]string) map[string]string {
+	for k, v := range params {
+		params[k] = url.QueryEscape(v)
+	}
+	return params
+}
+
+// isNotFound 是否为配置不存在的错误
+func isNotFound(err error) bool {
+	var nacosErr *nacos_error.NacosError
+	return errors.As(err, &nacosErr) && nacosErr.ErrorCode() == strconv.Itoa(http.StatusNotFound)
+}
@@ -0,0 +1,171 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package nacos
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/common/constant"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Config nacos配置源配置
+type Config struct {
+	Urls      []string `json:"urls" usage:"nacos 连接地址，例如http://127.0.0.1:8848/nacos"`
+	Namespace string   `json:"namespace" usage:"命名空间"`
+	Group     string   `json:"group" usage:"配置分组"`
+	DataId    string   `json:"dataId" usage:"配置id"`
+	Format    string   `json:"format" usage:"配置格式，默认根据dataId后缀推断，无法推断时为json"`
+	Username  string   `json:"username" usage:"用户名"`
+	Password  string   `json:"password" usage:"密码"`
+	TimeoutMs uint64   `json:"timeoutMs" usage:"请求超时时间"`
+	LogDir    string   `json:"logDir" usage:"nacos客户端日志目录"`
+	CacheDir  string   `json:"cacheDir" usage:"nacos客户端缓存目录"`
+	client    config_client.IConfigClient
+}
+
+// DefaultConfig 默认配置
+func DefaultConfig() *Config {
+	return &Config{
+		Urls:      []string{"http://127.0.0.1:8848/nacos"},
+		Group:     constant.DEFAULT_GROUP,
+		TimeoutMs: 5000,
+		LogDir:    "/tmp/nacos/log",
+		CacheDir:  "/tmp/nacos/cache",
+	}
+}
+
+// RawConfig 根据key解析配置
+func RawConfig(key string) *Config {
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := DefaultConfig()
+	if err := config.FromContext(ctx).Get(key).Scan(conf); err != nil {
+		panic(err)
+	}
+	return conf
+}
+
+// ScanConfig 根据名称解析配置
+func ScanConfig(name string) *Config {
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.config.source.nacos."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.config.source.nacos",
+		Named:       true,
+		Description: "nacos config source",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
+}
+
+// WithUrls 设置连接地址
+func (c *Config) WithUrls(urls ...string) *Config {
+	c.Urls = urls
+	return c
+}
+
+// WithNamespace 设置命名空间
+func (c *Config) WithNamespace(namespace string) *Config {
+	c.Namespace = namespace
+	return c
+}
+
+// WithGroup 设置配置分组
+func (c *Config) WithGroup(group string) *Config {
+	c.Group = group
+	return c
+}
+
+// WithDataId 设置配置id
+func (c *Config) WithDataId(dataId string) *Config {
+	c.DataId = dataId
+	return c
+}
+
+// WithFormat 设置配置格式
+func (c *Config) WithFormat(format string) *Config {
+	c.Format = format
+	return c
+}
+
+// WithClient 使用已创建的nacos配置客户端，设置后忽略连接相关配置
+func (c *Config) WithClient(client config_client.IConfigClient) *Config {
+	c.client = client
+	return c
+}
+
+// Build 构建配置源
+func (c *Config) Build() config.Source {
+	return NewSource(c)
+}
+
+// newClient 创建通过开放接口访问nacos的配置客户端
+func (c *Config) newClient() (config_client.IConfigClient, error) {
+	if c.client != nil {
+		return c.client, nil
+	}
+	servers := make([]constant.ServerConfig, 0, len(c.Urls))
+	for _, rawURL := range c.Urls {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, err
+		}
+		port, err := strconv.ParseUint(u.Port(), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("nacos url %s: invalid port", rawURL)
+		}
+		// 未指定上下文路径时使用nacos默认的/nacos，登录接口不会补全默认路径
+		contextPath := strings.TrimSuffix(u.Path, "/")
+		if contextPath == "" {
+			contextPath = constant.WEB_CONTEXT
+		}
+		servers = append(servers, *constant.NewServerConfig(
+			u.Hostname(),
+			port,
+			constant.WithScheme(u.Scheme),
+			constant.WithContextPath(contextPath),
+		))
+	}
+	return newHTTPClient(servers, *constant.NewClientConfig(
+		constant.WithNamespaceId(c.Namespace),
+		constant.WithTimeoutMs(c.TimeoutMs),
+		constant.WithUsername(c.Username),
+		constant.WithPassword(c.Password),
+		constant.WithLogDir(c.LogDir),
+		constant.WithCacheDir(c.CacheDir),
+		constant.WithNotLoadCacheAtStart(true),
+	))
+}
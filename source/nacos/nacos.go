@@ -0,0 +1,169 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package nacos
+
+import (
+	"fmt"
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/logger"
+	"github.com/nacos-group/nacos-sdk-go/v2/clients/config_client"
+	"github.com/nacos-group/nacos-sdk-go/v2/vo"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+type nacosSource struct {
+	client  config_client.IConfigClient
+	config  *Config
+	err     error
+	mu      sync.Mutex
+	changed chan struct{}
+}
+
+// Read 读取配置
+func (n *nacosSource) Read() (*config.DataSet, error) {
+	if n.err != nil {
+		return nil, n.err
+	}
+	content, err := n.client.GetConfig(n.param())
+	if err != nil {
+		return nil, fmt.Errorf("read nacos config %s: %w", n.config.DataId, err)
+	}
+	// 配置不存在时为空配置
+	if content == "" {
+		content = "{}"
+		if n.getUnmarshal() != "json" {
+			content = ""
+		}
+	}
+	dataSet := &config.DataSet{
+		Format:    n.getUnmarshal(),
+		Source:    n.String(),
+		Timestamp: time.Now(),
+		Data:      []byte(content),
+	}
+	dataSet.Checksum = dataSet.Sum()
+	return dataSet, nil
+}
+
+// Write 通过PublishConfig发布配置，格式需要与配置源一致
+func (n *nacosSource) Write(dataSet *config.DataSet) error {
+	if n.err != nil {
+		return n.err
+	}
+	if dataSet.Format != n.getUnmarshal() {
+		return fmt.Errorf("write nacos config %s: format %s mismatch %s", n.config.DataId, dataSet.Format, n.getUnmarshal())
+	}
+	param := n.param()
+	param.Content = string(dataSet.Data)
+	param.Type = n.getUnmarshal()
+	ok, err := n.client.PublishConfig(param)
+	if err != nil {
+		return fmt.Errorf("write nacos config %s: %w", n.config.DataId, err)
+	}
+	if !ok {
+		return fmt.Errorf("write nacos config %s: publish failed", n.config.DataId)
+	}
+	return nil
+}
+
+// IsChanged 配置变化通道
+func (n *nacosSource) IsChanged() <-chan struct{} {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.changed
+}
+
+// Watch 监听配置，nacos回调配置变化时通知
+func (n *nacosSource) Watch() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.err != nil || n.changed != nil {
+		return
+	}
+	n.changed = make(chan struct{}, 1)
+	param := n.param()
+	param.OnChange = func(_, _, _, _ string) {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if n.changed == nil {
+			return
+		}
+		select {
+		case n.changed <- struct{}{}:
+		default:
+		}
+	}
+	// 监听失败不影响读写，取消监听状态以便下次重新监听
+	if err := n.client.ListenConfig(param); err != nil {
+		n.changed = nil
+		logger.FrameLogger.Errord("watch nacos config", logger.FieldMod(errors.ModConfig), logger.FieldString("dataId", n.config.DataId), logger.FieldErr(err))
+	}
+}
+
+// UnWatch 取消监听，不持有锁调用CancelListenConfig，避免与进行中的回调互相等待
+func (n *nacosSource) UnWatch() {
+	n.mu.Lock()
+	changed := n.changed
+	n.changed = nil
+	n.mu.Unlock()
+	if changed == nil {
+		return
+	}
+	_ = n.client.CancelListenConfig(n.param())
+	close(changed)
+}
+
+// String 配置源名称
+func (n *nacosSource) String() string {
+	return "nacos"
+}
+
+// param 配置请求参数
+func (n *nacosSource) param() vo.ConfigParam {
+	return vo.ConfigParam{
+		DataId: n.config.DataId,
+		Group:  n.config.Group,
+	}
+}
+
+// getUnmarshal 获取配置格式
+func (n *nacosSource) getUnmarshal() string {
+	if n.config.Format != "" {
+		return n.config.Format
+	}
+	ext := strings.TrimPrefix(path.Ext(n.config.DataId), ".")
+	if _, ok := config.Unmarshals[ext]; ok {
+		return ext
+	}
+	return "json"
+}
+
+// NewSource 创建nacos配置源
+func NewSource(c *Config) config.Source {
+	client, err := c.newClient()
+	if err == nil && c.DataId == "" {
+		err = fmt.Errorf("nacos source: dataId is required")
+	}
+	return &nacosSource{
+		client: client,
+		config: c,
+		err:    err,
+	}
+}
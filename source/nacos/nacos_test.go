@@ -0,0 +1,281 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package nacos
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"github.com/go-ceres/go-ceres/config"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeServer 通过httptest模拟nacos的配置开放接口，内容的md5变化时返回监听请求
+type fakeServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	configs  map[string]string // 键为namespace/group/dataId
+	changed  chan struct{}     // 发布配置时关闭并重新创建
+	username string
+	password string
+	failed   bool // 为true时配置接口返回500
+}
+
+// newFakeServer 创建模拟nacos服务，接口挂载在contextPath下
+func newFakeServer(t *testing.T, contextPath string) *fakeServer {
+	s := &fakeServer{
+		configs: make(map[string]string),
+		changed: make(chan struct{}),
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(contextPath+"/v1/auth/users/login", s.login)
+	mux.HandleFunc(contextPath+"/v1/cs/configs", s.handleConfigs)
+	mux.HandleFunc(contextPath+"/v1/cs/configs/listener", s.listen)
+	s.Server = httptest.NewServer(mux)
+	t.Cleanup(s.Close)
+	return s
+}
+
+// config 创建连接到模拟服务的真实配置，日志和缓存写入临时目录
+func (s *fakeServer) config(t *testing.T, path string) *Config {
+	conf := DefaultConfig().WithUrls(s.URL + path)
+	conf.LogDir = t.TempDir()
+	conf.CacheDir = t.TempDir()
+	return conf
+}
+
+func (s *fakeServer) publish(namespace, group, dataId, content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configs[namespace+"/"+group+"/"+dataId] = content
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *fakeServer) get(namespace, group, dataId string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, ok := s.configs[namespace+"/"+group+"/"+dataId]
+	return content, ok
+}
+
+func (s *fakeServer) setFailed(failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed = failed
+}
+
+func (s *fakeServer) login(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("username") != s.username || r.FormValue("password") != s.password {
+		http.Error(w, "unknown user", http.StatusForbidden)
+		return
+	}
+	_, _ = w.Write([]byte(`{"accessToken":"token","tokenTtl":18000,"globalAdmin":true}`))
+}
+
+// authorized 开启鉴权时检查登录获得的token
+func (s *fakeServer) authorized(w http.ResponseWriter, r *http.Request) bool {
+	if s.username != "" && r.FormValue("accessToken") != "token" {
+		http.Error(w, "unknown user", http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+func (s *fakeServer) handleConfigs(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	s.mu.Lock()
+	failed := s.failed
+	s.mu.Unlock()
+	if failed {
+		http.Error(w, "server error", http.StatusInternalServerError)
+		return
+	}
+	namespace, group, dataId := r.FormValue("tenant"), r.FormValue("group"), r.FormValue("dataId")
+	switch r.Method {
+	case http.MethodGet:
+		content, ok := s.get(namespace, group, dataId)
+		if !ok {
+			http.Error(w, "config data not exist", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	case http.MethodPost:
+		s.publish(namespace, group, dataId, r.FormValue("content"))
+		_, _ = w.Write([]byte("true"))
+	default:
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// listen 长轮询，监听的配置md5变化时返回变化的配置，否则等待发布或超时，超时时间比服务端短以便测试结束
+func (s *fakeServer) listen(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(w, r) {
+		return
+	}
+	timeout := time.After(100 * time.Millisecond)
+	for {
+		s.mu.Lock()
+		changed := s.changed
+		s.mu.Unlock()
+		var res strings.Builder
+		for _, item := range strings.Split(r.FormValue("Listening-Configs"), "\x01") {
+			parts := strings.Split(item, "\x02")
+			if len(parts) < 3 {
+				continue
+			}
+			namespace := ""
+			if len(parts) > 3 {
+				namespace = parts[3]
+			}
+			content, _ := s.get(namespace, parts[1], parts[0])
+			sum := md5.Sum([]byte(content))
+			if hex.EncodeToString(sum[:]) != parts[2] {
+				res.WriteString(parts[0] + "\x02" + parts[1] + "\x02" + namespace + "\x01")
+			}
+		}
+		if res.Len() > 0 {
+			_, _ = w.Write([]byte(url.QueryEscape(res.String())))
+			return
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestNacosSource_Read(t *testing.T) {
+	server := newFakeServer(t, "/ctx")
+	server.publish("dev", "DEFAULT_GROUP", "app.yaml", "app:\n  port: 80\n")
+	server.publish("", "DEFAULT_GROUP", "app.yaml", "app:\n  port: 81\n")
+
+	source := server.config(t, "/ctx").WithNamespace("dev").WithDataId("app.yaml").Build()
+	ds, err := source.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ds.Format != "yaml" || string(ds.Data) != "app:\n  port: 80\n" || ds.Checksum != ds.Sum() {
+		t.Fatalf("dataSet = %+v", ds)
+	}
+	// 配置不存在时为空配置
+	ds, err = server.config(t, "/ctx").WithNamespace("dev").WithDataId("missing").Build().Read()
+	if err != nil || ds.Format != "json" || string(ds.Data) != "{}" {
+		t.Fatalf("Read() = %+v, %v", ds, err)
+	}
+	if _, err := server.config(t, "/ctx").Build().Read(); err == nil {
+		t.Fatal("expect error without dataId")
+	}
+	if _, err := DefaultConfig().WithUrls("http://127.0.0.1/nacos").WithDataId("app").Build().Read(); err == nil {
+		t.Fatal("expect error without port")
+	}
+}
+
+func TestNacosSource_DefaultContextPath(t *testing.T) {
+	server := newFakeServer(t, "/nacos")
+	server.username, server.password = "nacos", "secret"
+	server.publish("", "ceres", "app", `{"app":{"port":80}}`)
+
+	conf := server.config(t, "").WithGroup("ceres").WithDataId("app")
+	conf.Username, conf.Password = "nacos", "secret"
+	ds, err := conf.Build().Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(ds.Data) != `{"app":{"port":80}}` {
+		t.Fatalf("data = %s", ds.Data)
+	}
+}
+
+func TestNacosSource_Watch(t *testing.T) {
+	server := newFakeServer(t, "/ctx")
+	server.publish("dev", "ceres", "app", `{"app":{"port":80}}`)
+
+	c := config.NewConfig()
+	if err := c.LoadSource(server.config(t, "/ctx").WithNamespace("dev").WithDataId("app").WithGroup("ceres").Build()); err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan config.Values, 1)
+	c.OnChange(func(v config.Values) {
+		changed <- v
+	})
+	c.Watch()
+	defer c.UnWatch()
+
+	server.publish("dev", "ceres", "app", `{"app":{"port":81}}`)
+	select {
+	case v := <-changed:
+		if port := v.Get("app.port").Int(0); port != 81 {
+			t.Fatalf("app.port = %d, want 81", port)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("change not notified")
+	}
+}
+
+func TestNacosSource_Write(t *testing.T) {
+	server := newFakeServer(t, "/ctx")
+	server.publish("", "DEFAULT_GROUP", "app.json", `{"app":{"port":80}}`)
+
+	c := config.NewConfig()
+	source := server.config(t, "/ctx").WithDataId("app.json").Build()
+	if err := c.LoadSource(source); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Set("app.name", "ceres"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write(); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := server.get("", "DEFAULT_GROUP", "app.json")
+	if !strings.Contains(content, `"name": "ceres"`) || !strings.Contains(content, `"port": 80`) {
+		t.Fatalf("published content = %s", content)
+	}
+	if err := source.Write(&config.DataSet{Format: "yaml", Data: []byte("a: 1")}); err == nil {
+		t.Fatal("expect format mismatch error")
+	}
+}
+
+func TestNacosSource_WatchError(t *testing.T) {
+	server := newFakeServer(t, "/ctx")
+	server.publish("", "ceres", "app", `{"app":{"port":80}}`)
+	server.setFailed(true)
+
+	source := server.config(t, "/ctx").WithDataId("app").WithGroup("ceres").Build()
+	source.Watch()
+	if source.IsChanged() != nil {
+		t.Fatal("failed watch should not keep changed channel")
+	}
+	// 恢复后可以重新读取和监听
+	server.setFailed(false)
+	if _, err := source.Read(); err != nil {
+		t.Fatalf("read after recovery err = %v", err)
+	}
+	source.Watch()
+	defer source.UnWatch()
+	if source.IsChanged() == nil {
+		t.Fatal("watch should succeed after recovery")
+	}
+}
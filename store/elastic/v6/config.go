@@ -16,6 +16,7 @@
 package elasticsearch
 
 import (
+	"context"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/logger"
 	"github.com/olivere/elastic"
@@ -23,29 +24,39 @@ import (
 )
 
 type Config struct {
-	Address    []string     `json:"address"`  // es连接地址
-	Scheme     string       `json:"scheme"`   // http协议
-	Username   string       `json:"username"` // es用户名
-	Password   string       `json:"password"` // es密码
-	Sniff      bool         `json:"sniff"`    // 是否使用内部存活
+	Address    []string     `json:"address" usage:"es连接地址"`
+	Scheme     string       `json:"scheme" usage:"http协议"`
+	Username   string       `json:"username" usage:"es用户名"`
+	Password   string       `json:"password" usage:"es密码"`
+	Sniff      bool         `json:"sniff" usage:"是否使用内部存活"`
 	httpClient *http.Client // http客户端
 	options    []elastic.ClientOptionFunc
 	logger     *logger.Logger // 日志组件
 }
 
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Address: []string{"http://127.0.0.1:9200"},
 		Scheme:  "http",
 		Sniff:   false,
-		logger:  logger.FrameLogger.With(logger.FieldMod("store.elastic")),
+		logger:  logger.FrameFromContext(ctx).With(logger.FieldMod("store.elastic")),
 	}
 }
 
 // RawConfig 根据配置key读取配置
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	err := config.Get(key).Scan(conf)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(conf)
 	if err != nil {
 		conf.logger.Panicd("parse config error", logger.FieldAny("key", key), logger.FieldValue(conf))
 	}
@@ -54,7 +65,27 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 根据配置名称读取配置
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.store.elastic." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.store.elastic."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.store.elastic",
+		Named:       true,
+		Description: "elasticsearch client",
+		Default: func() interface{} {
+			return DefaultConfig()
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // WithTransport 单独设置http客户端的transport
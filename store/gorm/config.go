@@ -16,6 +16,7 @@
 package gorm
 
 import (
+	"context"
 	"fmt"
 	"github.com/go-ceres/go-ceres/config"
 	"github.com/go-ceres/go-ceres/errors"
@@ -27,12 +28,12 @@ import (
 
 // Config 配置信息
 type Config struct {
-	Drive           string        `json:"drive"`             // 驱动
-	DNS             string        `json:"dns"`               // 连接字符串
-	Debug           bool          `json:"debug"`             // 是否开启调试
-	MaxIdleConns    int           `json:"max_idle_conns"`    // 最大空闲连接数
-	MaxOpenConns    int           `json:"max_open_conns"`    // 最大活动连接数
-	ConnMaxLifetime time.Duration `json:"conn_max_lifetime"` // 连接的最大存活时间
+	Drive           string        `json:"drive" usage:"驱动"`
+	DNS             string        `json:"dns" usage:"连接字符串"`
+	Debug           bool          `json:"debug" usage:"是否开启调试"`
+	MaxIdleConns    int           `json:"max_idle_conns" usage:"最大空闲连接数"`
+	MaxOpenConns    int           `json:"max_open_conns" usage:"最大活动连接数"`
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime" usage:"连接的最大存活时间"`
 
 	*GormConfig                // gorm初始化配置
 	*LogConfig                 // 日志配置
@@ -42,9 +43,9 @@ type Config struct {
 
 // LogConfig 日志配置
 type LogConfig struct {
-	SlowThreshold time.Duration // 日志时间阈值
-	Colorful      bool          // 是否开启日志颜色区别
-	LogLevel      string        // 日志等级
+	SlowThreshold time.Duration `usage:"日志时间阈值"`
+	Colorful      bool          `usage:"是否开启日志颜色区别"`
+	LogLevel      string        `usage:"日志等级"`
 }
 
 // DefaultLogConfig 默认的日志配置
@@ -60,6 +61,11 @@ type GormConfig gorm.Config
 
 // DefaultConfig 默认gorm配置
 func DefaultConfig() *Config {
+	return defaultConfig(context.Background())
+}
+
+// defaultConfig 根据上下文生成默认配置
+func defaultConfig(ctx context.Context) *Config {
 	return &Config{
 		Drive:           "mysql",
 		DNS:             "",
@@ -68,15 +74,20 @@ func DefaultConfig() *Config {
 		MaxOpenConns:    100,
 		ConnMaxLifetime: time.Hour,
 		GormConfig:      &GormConfig{},
-		logger:          logger.FrameLogger.With(logger.FieldMod(errors.ModStoreGorm)),
+		logger:          logger.FrameFromContext(ctx).With(logger.FieldMod(errors.ModStoreGorm)),
 		LogConfig:       DefaultLogConfig(),
 	}
 }
 
 // RawConfig 根据key读取配置信息
 func RawConfig(key string) *Config {
-	conf := DefaultConfig()
-	err := config.Get(key).Scan(conf)
+	return RawConfigContext(context.Background(), key)
+}
+
+// RawConfigContext 根据key从上下文中的配置管理器读取配置
+func RawConfigContext(ctx context.Context, key string) *Config {
+	conf := defaultConfig(ctx)
+	err := config.FromContext(ctx).Get(key).Scan(conf)
 	if err != nil {
 		conf.logger.Panicd("parse config error", logger.FieldErr(err), logger.FieldAny("config", conf))
 	}
@@ -85,7 +96,30 @@ func RawConfig(key string) *Config {
 
 // ScanConfig 根据名称读取配置信息
 func ScanConfig(name string) *Config {
-	return RawConfig("ceres.store.gorm." + name)
+	return ScanConfigContext(context.Background(), name)
+}
+
+// ScanConfigContext 根据名称从上下文中的配置管理器读取配置
+func ScanConfigContext(ctx context.Context, name string) *Config {
+	return RawConfigContext(ctx, "ceres.store.gorm."+name)
+}
+
+// init 注册组件配置
+func init() {
+	config.RegisterComponent(config.Component{
+		Prefix:      "ceres.store.gorm",
+		Named:       true,
+		Description: "gorm database",
+		Default: func() interface{} {
+			// gorm配置中包含方法，无法序列化，模板中使用默认值
+			conf := DefaultConfig()
+			conf.GormConfig = nil
+			return conf
+		},
+		Scan: func(ctx context.Context, name string) interface{} {
+			return ScanConfigContext(ctx, name)
+		},
+	})
 }
 
 // initLogger 初始化日志
@@ -0,0 +1,160 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+// Package testing 进程内启动完整引擎的测试工具，
+// 使用内存配置、内存注册中心和独立的健康检查组件，服务监听随机端口，
+// 测试结束时自动停止并取消引擎的信号监听和健康检查监听
+package testing
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ceres/go-ceres"
+	"github.com/go-ceres/go-ceres/config"
+	"github.com/go-ceres/go-ceres/health"
+	"github.com/go-ceres/go-ceres/registry"
+	"github.com/go-ceres/go-ceres/registry/memory"
+	"github.com/go-ceres/go-ceres/server"
+	"github.com/go-ceres/go-ceres/server/gin"
+	cgrpc "github.com/go-ceres/go-ceres/server/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"math"
+	"testing"
+	"time"
+)
+
+// StartTimeout 等待引擎启动的超时时间
+var StartTimeout = 10 * time.Second
+
+// App 测试用的引擎
+type App struct {
+	t        testing.TB
+	Engine   *ceres.Engine     // 引擎
+	Config   config.Config     // 引擎使用的内存配置
+	Registry registry.Registry // 内存注册中心
+	Health   *health.Health    // 引擎独立的健康检查组件
+	started  bool
+	done     chan error // 引擎运行结束
+}
+
+// New 根据内存配置创建引擎，不解析命令行，key可以是以.分隔的路径
+func New(t testing.TB, values map[string]interface{}, opts ...ceres.Option) *App {
+	t.Helper()
+	conf := config.NewConfig()
+	for key, value := range values {
+		if err := conf.Set(key, value); err != nil {
+			t.Fatalf("ceres testing: set config %s: %v", key, err)
+		}
+	}
+	h := health.New()
+	opts = append([]ceres.Option{ceres.WithConfig(conf), ceres.WithHealth(h), ceres.WithoutCmd()}, opts...)
+	eng := ceres.NewEngine(opts...)
+	a := &App{
+		t:      t,
+		Engine: eng,
+		Config: conf,
+		Health: h,
+	}
+	// 未启动的引擎同样需要停止，取消初始化时添加的监听
+	t.Cleanup(a.stop)
+	if err := eng.MustSetup(); err != nil {
+		t.Fatalf("ceres testing: setup engine: %v", err)
+	}
+	a.Registry = memory.NewRegistry()
+	eng.SetRegistry(a.Registry)
+	return a
+}
+
+// Gin 添加监听随机端口的gin服务，返回http访问地址，如http://127.0.0.1:12345
+func (a *App) Gin(name string, register func(s *gin.Server)) string {
+	a.t.Helper()
+	srv := gin.ScanConfigContext(a.Engine.Context(), name).WithHost("127.0.0.1").WithPort(0).Build()
+	if register != nil {
+		register(srv)
+	}
+	a.addServer(srv)
+	return fmt.Sprintf("http://%s", srv.Config.Address())
+}
+
+// GRPC 添加监听随机端口的grpc服务，返回连接该服务的客户端，测试结束时关闭
+func (a *App) GRPC(name string, register func(s *grpc.Server)) *grpc.ClientConn {
+	a.t.Helper()
+	srv := cgrpc.ScanConfigContext(a.Engine.Context(), name).WithHost("127.0.0.1").WithPort(0).Build()
+	if register != nil {
+		register(srv.Server)
+	}
+	a.addServer(srv)
+	conn, err := grpc.Dial(srv.Address(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		a.t.Fatalf("ceres testing: dial grpc %s: %v", name, err)
+	}
+	a.t.Cleanup(func() {
+		_ = conn.Close()
+	})
+	return conn
+}
+
+// Start 运行引擎并等待所有服务启动完成，测试结束时停止引擎
+func (a *App) Start() *App {
+	a.t.Helper()
+	if a.started {
+		return a
+	}
+	a.started = true
+	ready := make(chan struct{})
+	a.Engine.AfterStart("ceres.testing.ready", func(ctx context.Context) error {
+		close(ready)
+		return nil
+	}, ceres.HookPriority(math.MaxInt32))
+	done := make(chan error, 1)
+	a.done = done
+	go func() {
+		done <- a.Engine.Run()
+	}()
+	select {
+	case <-ready:
+	case err := <-done:
+		a.done = nil
+		a.t.Fatalf("ceres testing: engine exit: %v", err)
+	case <-time.After(StartTimeout):
+		a.t.Fatalf("ceres testing: engine start timeout")
+	}
+	return a
+}
+
+// stop 停止引擎，已启动时等待运行结束
+func (a *App) stop() {
+	_ = a.Engine.Stop()
+	if a.done == nil {
+		return
+	}
+	select {
+	case <-a.done:
+	case <-time.After(StartTimeout):
+		a.t.Errorf("ceres testing: engine stop timeout")
+	}
+}
+
+// addServer 添加服务到引擎，引擎运行后添加的服务不会启动
+func (a *App) addServer(srv server.Server) {
+	a.t.Helper()
+	if a.started {
+		a.t.Fatalf("ceres testing: add server after start")
+	}
+	if err := a.Engine.Server(srv); err != nil {
+		a.t.Fatalf("ceres testing: add server: %v", err)
+	}
+}
@@ -0,0 +1,104 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package testing
+
+import (
+	"context"
+	"errors"
+	ginx "github.com/gin-gonic/gin"
+	"github.com/go-ceres/go-ceres/health"
+	"github.com/go-ceres/go-ceres/server/gin"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestApp(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		name := name
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			app := New(t, map[string]interface{}{
+				"ceres.server.gin.default.name": name,
+			})
+			base := app.Gin("default", func(s *gin.Server) {
+				s.GET("/name", func(c *ginx.Context) {
+					c.String(http.StatusOK, s.Config.Name)
+				})
+			})
+			conn := app.GRPC("default", nil)
+			app.Start()
+
+			resp, err := http.Get(base + "/name")
+			if err != nil {
+				t.Fatal(err)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			_ = resp.Body.Close()
+			if string(body) != name {
+				t.Fatalf("body = %s, want %s", body, name)
+			}
+
+			check, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Status != healthpb.HealthCheckResponse_SERVING {
+				t.Fatalf("status = %s", check.Status)
+			}
+
+			services, err := app.Registry.ListService()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(services) != 2 {
+				t.Fatalf("registered services = %d, want 2", len(services))
+			}
+		})
+	}
+}
+
+func TestAppSequential(t *testing.T) {
+	var first *App
+	for i, name := range []string{"first", "second"} {
+		i := i
+		t.Run(name, func(t *testing.T) {
+			app := New(t, nil)
+			if app.Health == health.DefaultHealth {
+				t.Fatal("harness engine should use its own health")
+			}
+			conn := app.GRPC("default", nil)
+			app.Start()
+			if i == 0 {
+				first = app
+				return
+			}
+			// 前一个引擎的健康检查不影响当前引擎
+			_ = first.Health.Register("down", health.CheckerFunc(func(ctx context.Context) error {
+				return errors.New("down")
+			}))
+			first.Health.Refresh(context.Background())
+			check, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Status != healthpb.HealthCheckResponse_SERVING {
+				t.Fatalf("status = %s", check.Status)
+			}
+		})
+	}
+}
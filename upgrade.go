@@ -0,0 +1,65 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/logger"
+	"github.com/go-ceres/go-ceres/utils/signalsx"
+	"github.com/go-ceres/go-ceres/utils/upgradex"
+	"sync/atomic"
+)
+
+// waitUpgrade 开启平滑升级时监听升级信号
+func (eng *Engine) waitUpgrade() {
+	if !eng.conf.Get("ceres.application.upgrade").Bool(false) {
+		return
+	}
+	eng.logger.Infod("init listen upgrade signal", logger.FieldMod(errors.ModApp))
+	eng.addSignal(signalsx.Upgrade(eng.upgrade))
+}
+
+// upgrade 平滑升级，启动新的二进制并移交监听，新进程就绪后优雅退出当前进程
+func (eng *Engine) upgrade() {
+	if !atomic.CompareAndSwapInt32(&eng.upgrading, 0, 1) {
+		eng.logger.Warnd("upgrade is in progress", logger.FieldMod(errors.ModApp))
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), eng.stopTimeout)
+	defer cancel()
+	eng.logger.Infod("upgrade start", logger.FieldMod(errors.ModApp))
+	process, err := upgradex.Upgrade(ctx)
+	if err != nil {
+		atomic.StoreInt32(&eng.upgrading, 0)
+		eng.logger.Errord("upgrade failed", logger.FieldMod(errors.ModApp), logger.FieldErr(err))
+		return
+	}
+	eng.logger.Infod("upgrade child ready, graceful stop", logger.FieldMod(errors.ModApp), logger.FieldAny("pid", process.Pid))
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), eng.stopTimeout)
+	defer stopCancel()
+	_ = eng.GracefulStop(stopCtx)
+}
+
+// notifyUpgradeReady 由平滑升级启动的进程就绪后通知父进程
+func (eng *Engine) notifyUpgradeReady() {
+	if !upgradex.IsChild() {
+		return
+	}
+	if err := upgradex.Ready(); err != nil {
+		eng.logger.Errord("notify upgrade ready", logger.FieldMod(errors.ModApp), logger.FieldErr(err))
+	}
+}
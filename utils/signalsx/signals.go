@@ -18,6 +18,7 @@ package signalsx
 import (
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 )
 
@@ -25,16 +26,39 @@ var shutdownSignals = []os.Signal{
 	syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGKILL,
 }
 
-func Shutdown(stop func(grace bool)) {
+// Shutdown 监听退出信号，返回取消监听的方法
+func Shutdown(stop func(grace bool)) func() {
 	ch := make(chan os.Signal, 2)
+	done := make(chan struct{})
 	signal.Notify(
 		ch,
 		shutdownSignals...,
 	)
 	go func() {
-		s := <-ch
+		var s os.Signal
+		select {
+		case s = <-ch:
+		case <-done:
+			return
+		}
 		go stop(s != syscall.SIGQUIT)
-		<-ch
+		select {
+		case <-ch:
+		case <-done:
+			return
+		}
 		os.Exit(128 + int(s.(syscall.Signal))) // second signal. Exit directly.
 	}()
+	return cancelFunc(ch, done)
+}
+
+// cancelFunc 取消信号监听并结束监听协程
+func cancelFunc(ch chan os.Signal, done chan struct{}) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(ch)
+			close(done)
+		})
+	}
 }
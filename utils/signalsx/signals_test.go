@@ -0,0 +1,82 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+//go:build !windows
+
+package signalsx
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestShutdownCancel(t *testing.T) {
+	called := make(chan bool, 1)
+	cancel := Shutdown(func(grace bool) {
+		called <- grace
+	})
+	cancel()
+	cancel()
+
+	// 取消后由测试自己接收信号，避免进程被默认行为结束
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGTERM)
+	defer signal.Stop(ch)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-ch:
+	case <-time.After(time.Second):
+		t.Fatal("signal not received")
+	}
+	select {
+	case <-called:
+		t.Fatal("canceled shutdown should not be called")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestUpgradeCancel(t *testing.T) {
+	called := make(chan struct{}, 1)
+	cancel := Upgrade(func() {
+		called <- struct{}{}
+	})
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-called:
+	case <-time.After(time.Second):
+		t.Fatal("upgrade not called")
+	}
+	cancel()
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGUSR2)
+	defer signal.Stop(ch)
+	if err := syscall.Kill(os.Getpid(), syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	<-ch
+	select {
+	case <-called:
+		t.Fatal("canceled upgrade should not be called")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,42 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+//go:build !windows
+
+package signalsx
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// Upgrade 监听平滑升级信号(SIGUSR2)，返回取消监听的方法
+func Upgrade(upgrade func()) func() {
+	ch := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(ch, syscall.SIGUSR2)
+	go func() {
+		for {
+			select {
+			case <-ch:
+				upgrade()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return cancelFunc(ch, done)
+}
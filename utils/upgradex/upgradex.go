@@ -0,0 +1,255 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+//go:build !windows
+
+// Package upgradex 通过继承监听文件描述符实现二进制平滑升级
+// 父进程将监听的文件描述符通过ExtraFiles传递给新启动的子进程，
+// 子进程通过环境变量得知继承的监听地址，直接复用而不是重新监听，
+// 子进程就绪后通知父进程，由父进程优雅退出
+package upgradex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// EnvListeners 继承的监听地址，按文件描述符顺序以逗号分隔，如：tcp://0.0.0.0:5201,tcp://0.0.0.0:5202
+	EnvListeners = "CERES_UPGRADE_LISTENERS"
+	// EnvReadyFd 子进程就绪后写入的管道文件描述符
+	EnvReadyFd = "CERES_UPGRADE_READY_FD"
+	// listenFdStart 继承的第一个文件描述符，0、1、2为标准输入输出
+	listenFdStart = 3
+)
+
+var errNotFiler = errors.New("listener does not support file")
+
+var (
+	mu        sync.Mutex
+	loadOnce  sync.Once
+	inherited = make(map[string]net.Listener) // 继承但尚未使用的监听
+	actives   []*activeListener               // 当前进程正在使用的监听
+)
+
+// activeListener 正在使用的监听，关闭后从正在使用的监听中移除
+type activeListener struct {
+	net.Listener
+	key  string
+	once sync.Once
+}
+
+// Close 关闭监听
+func (l *activeListener) Close() error {
+	l.once.Do(func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for i, active := range actives {
+			if active == l {
+				actives = append(actives[:i], actives[i+1:]...)
+				break
+			}
+		}
+	})
+	return l.Listener.Close()
+}
+
+// File 获取监听的文件描述符
+func (l *activeListener) File() (*os.File, error) {
+	lf, ok := l.Listener.(filer)
+	if !ok {
+		return nil, errNotFiler
+	}
+	return lf.File()
+}
+
+// filer 可以获取文件描述符的监听
+type filer interface {
+	File() (*os.File, error)
+}
+
+// Listen 监听地址，如果从父进程继承了该地址的监听则直接复用
+func Listen(network, address string) (net.Listener, error) {
+	loadOnce.Do(loadInherited)
+	key := listenerKey(network, address)
+	mu.Lock()
+	defer mu.Unlock()
+	l, ok := inherited[key]
+	if ok {
+		delete(inherited, key)
+	} else {
+		var err error
+		if l, err = net.Listen(network, address); err != nil {
+			return nil, err
+		}
+	}
+	active := &activeListener{Listener: l, key: key}
+	actives = append(actives, active)
+	return active, nil
+}
+
+// IsChild 当前进程是否由平滑升级启动
+func IsChild() bool {
+	return os.Getenv(EnvReadyFd) != ""
+}
+
+// Ready 子进程就绪后通知父进程，非升级启动的进程直接返回
+func Ready() error {
+	fdStr := os.Getenv(EnvReadyFd)
+	if fdStr == "" {
+		return nil
+	}
+	_ = os.Unsetenv(EnvReadyFd)
+	fd, err := strconv.Atoi(fdStr)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %s", EnvReadyFd, fdStr)
+	}
+	f := os.NewFile(uintptr(fd), "ready")
+	defer f.Close()
+	_, err = f.Write([]byte{1})
+	return err
+}
+
+// Upgrade 启动新的二进制并传递当前所有监听，等待子进程就绪后返回
+// ctx结束时子进程仍未就绪，则结束子进程并返回错误
+func Upgrade(ctx context.Context) (*os.Process, error) {
+	mu.Lock()
+	keys := make([]string, 0, len(actives))
+	files := make([]*os.File, 0, len(actives)+1)
+	for _, active := range actives {
+		f, err := active.File()
+		// 不支持文件描述符或已关闭的监听不再传递
+		if errors.Is(err, errNotFiler) || errors.Is(err, net.ErrClosed) {
+			continue
+		}
+		if err != nil {
+			mu.Unlock()
+			closeFiles(files)
+			return nil, fmt.Errorf("get listener %s file: %w", active.key, err)
+		}
+		keys = append(keys, active.key)
+		files = append(files, f)
+	}
+	mu.Unlock()
+	defer closeFiles(files)
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	files = append(files, w)
+
+	path, err := os.Executable()
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.Command(path, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = files
+	cmd.Env = append(cleanEnv(os.Environ()),
+		EnvListeners+"="+strings.Join(keys, ","),
+		EnvReadyFd+"="+strconv.Itoa(listenFdStart+len(keys)),
+	)
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	// 关闭父进程持有的写端，子进程退出时读端才能收到EOF
+	_ = w.Close()
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := r.Read(buf); err != nil {
+			if err == io.EOF {
+				err = errors.New("upgrade child exited before ready")
+			}
+			ready <- err
+			return
+		}
+		ready <- nil
+	}()
+	select {
+	case err := <-ready:
+		if err != nil {
+			_ = cmd.Process.Kill()
+			_ = cmd.Wait()
+			return nil, err
+		}
+		// 回收子进程，避免父进程先退出前子进程成为僵尸进程
+		go func() {
+			_ = cmd.Wait()
+		}()
+		return cmd.Process, nil
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, ctx.Err()
+	}
+}
+
+// loadInherited 加载从父进程继承的监听
+func loadInherited() {
+	value := os.Getenv(EnvListeners)
+	if value == "" {
+		return
+	}
+	_ = os.Unsetenv(EnvListeners)
+	mu.Lock()
+	defer mu.Unlock()
+	for i, key := range strings.Split(value, ",") {
+		f := os.NewFile(uintptr(listenFdStart+i), key)
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			continue
+		}
+		inherited[key] = l
+	}
+}
+
+// listenerKey 监听的唯一标识
+func listenerKey(network, address string) string {
+	return network + "://" + address
+}
+
+// cleanEnv 删除升级相关的环境变量
+func cleanEnv(env []string) []string {
+	res := make([]string, 0, len(env))
+	for _, e := range env {
+		if strings.HasPrefix(e, EnvListeners+"=") || strings.HasPrefix(e, EnvReadyFd+"=") {
+			continue
+		}
+		res = append(res, e)
+	}
+	return res
+}
+
+// closeFiles 关闭文件
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
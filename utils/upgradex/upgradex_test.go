@@ -0,0 +1,180 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+//go:build !windows
+
+package upgradex
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	envHelper = "CERES_UPGRADEX_HELPER" // 子进程的运行模式
+	envExpect = "CERES_UPGRADEX_EXPECT" // 子进程期望继承的监听
+)
+
+// TestMain 作为平滑升级的子进程启动时，执行子进程逻辑后退出
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(envHelper); mode != "" {
+		os.Exit(runChild(mode))
+	}
+	os.Exit(m.Run())
+}
+
+// runChild 子进程逻辑
+func runChild(mode string) int {
+	switch mode {
+	case "exit":
+		return 1
+	case "hang":
+		time.Sleep(time.Minute)
+		return 0
+	}
+	expect := os.Getenv(envExpect)
+	if os.Getenv(EnvListeners) != expect {
+		return 2
+	}
+	// 继承的监听按父进程监听时的地址匹配
+	l, err := Listen("tcp", strings.TrimPrefix(expect, "tcp://"))
+	if err != nil {
+		return 3
+	}
+	defer l.Close()
+	if err := Ready(); err != nil {
+		return 4
+	}
+	conn, err := l.Accept()
+	if err != nil {
+		return 5
+	}
+	defer conn.Close()
+	_, _ = conn.Write([]byte("child"))
+	return 0
+}
+
+func TestUpgrade(t *testing.T) {
+	closed, err := Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = closed.Close()
+	l, err := Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	address := l.Addr().String()
+	t.Setenv(envHelper, "ready")
+	// 已关闭的监听不应传递给子进程
+	t.Setenv(envExpect, listenerKey("tcp", "127.0.0.1:0"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	process, err := Upgrade(ctx)
+	if err != nil {
+		t.Fatalf("upgrade err = %v", err)
+	}
+	if process == nil {
+		t.Fatal("upgrade should return child process")
+	}
+
+	// 父进程不再接收连接，连接由继承了同一监听的子进程处理
+	conn, err := net.Dial("tcp", address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	data, err := io.ReadAll(conn)
+	if err != nil || string(data) != "child" {
+		t.Fatalf("read = %q, %v, want child", data, err)
+	}
+}
+
+func TestUpgrade_ChildExit(t *testing.T) {
+	t.Setenv(envHelper, "exit")
+	_, err := Upgrade(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "exited before ready") {
+		t.Fatalf("upgrade err = %v, want exited before ready", err)
+	}
+}
+
+func TestUpgrade_Timeout(t *testing.T) {
+	t.Setenv(envHelper, "hang")
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := Upgrade(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("upgrade err = %v, want deadline exceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("upgrade took %v, child should be killed on timeout", elapsed)
+	}
+}
+
+func TestListen_Close(t *testing.T) {
+	l, err := Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = l.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	for _, active := range actives {
+		if active == l {
+			t.Fatal("closed listener should be removed from actives")
+		}
+	}
+}
+
+func TestReady(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	t.Setenv(EnvReadyFd, "")
+	if err := Ready(); err != nil {
+		t.Fatalf("ready without fd err = %v", err)
+	}
+	t.Setenv(EnvReadyFd, "x")
+	if err := Ready(); err == nil {
+		t.Fatal("ready with invalid fd should fail")
+	}
+	t.Setenv(EnvReadyFd, strconv.Itoa(int(w.Fd())))
+	if !IsChild() {
+		t.Fatal("should be child with ready fd")
+	}
+	if err := Ready(); err != nil {
+		t.Fatalf("ready err = %v", err)
+	}
+	buf := make([]byte, 1)
+	if n, err := r.Read(buf); err != nil || n != 1 {
+		t.Fatalf("read ready pipe = %d, %v", n, err)
+	}
+	if IsChild() {
+		t.Fatal("ready fd should be unset after ready")
+	}
+}
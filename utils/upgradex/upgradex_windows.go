@@ -0,0 +1,43 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package upgradex
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+)
+
+// Listen 监听地址，windows不支持继承监听
+func Listen(network, address string) (net.Listener, error) {
+	return net.Listen(network, address)
+}
+
+// IsChild 当前进程是否由平滑升级启动
+func IsChild() bool {
+	return false
+}
+
+// Ready 子进程就绪后通知父进程
+func Ready() error {
+	return nil
+}
+
+// Upgrade windows不支持平滑升级
+func Upgrade(ctx context.Context) (*os.Process, error) {
+	return nil, errors.New("upgrade is not supported on windows")
+}
@@ -0,0 +1,291 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-ceres/go-ceres/errors"
+	"github.com/go-ceres/go-ceres/logger"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+// RestartPolicy 后台任务重启策略
+type RestartPolicy int
+
+const (
+	RestartNever     RestartPolicy = iota // 退出后不再重启
+	RestartOnFailure                      // 返回错误或panic时重启
+	RestartAlways                         // 只要未停止就重启
+)
+
+// String 重启策略名称
+func (p RestartPolicy) String() string {
+	switch p {
+	case RestartOnFailure:
+		return "on-failure"
+	case RestartAlways:
+		return "always"
+	default:
+		return "never"
+	}
+}
+
+// 后台任务状态
+const (
+	WorkerStatusPending  = "pending"  // 等待启动
+	WorkerStatusRunning  = "running"  // 运行中
+	WorkerStatusBackoff  = "backoff"  // 等待重启
+	WorkerStatusStopped  = "stopped"  // 已停止
+	WorkerStatusFailed   = "failed"   // 失败且不再重启
+	WorkerStatusFinished = "finished" // 正常退出且不再重启
+)
+
+// WorkerFunc 后台任务方法，ctx在引擎停止时取消
+type WorkerFunc func(ctx context.Context) error
+
+// WorkerOption 后台任务选项
+type WorkerOption func(w *worker)
+
+// WorkerRestart 设置重启策略，默认RestartOnFailure
+func WorkerRestart(policy RestartPolicy) WorkerOption {
+	return func(w *worker) {
+		w.policy = policy
+	}
+}
+
+// WorkerBackoff 设置重启的退避时间，每次重启翻倍直到max
+func WorkerBackoff(min, max time.Duration) WorkerOption {
+	return func(w *worker) {
+		w.minBackoff = min
+		w.maxBackoff = max
+	}
+}
+
+// WorkerMaxRestarts 设置最大重启次数，0表示不限制
+func WorkerMaxRestarts(n int) WorkerOption {
+	return func(w *worker) {
+		w.maxRestarts = n
+	}
+}
+
+// WorkerState 后台任务状态信息
+type WorkerState struct {
+	Name      string    `json:"name"`
+	Status    string    `json:"status"`
+	Policy    string    `json:"policy"`
+	Restarts  int       `json:"restarts"`
+	LastError string    `json:"lastError,omitempty"`
+	StartedAt time.Time `json:"startedAt,omitempty"`
+	StoppedAt time.Time `json:"stoppedAt,omitempty"`
+}
+
+// worker 受监管的后台任务
+type worker struct {
+	mu          sync.RWMutex
+	fn          WorkerFunc
+	policy      RestartPolicy
+	minBackoff  time.Duration
+	maxBackoff  time.Duration
+	maxRestarts int
+	logger      *logger.Logger
+	state       WorkerState
+}
+
+// newWorker 创建后台任务
+func newWorker(name string, fn WorkerFunc, log *logger.Logger, opts ...WorkerOption) *worker {
+	w := &worker{
+		fn:         fn,
+		policy:     RestartOnFailure,
+		minBackoff: time.Second,
+		maxBackoff: time.Minute,
+		logger:     log,
+		state: WorkerState{
+			Name:   name,
+			Status: WorkerStatusPending,
+		},
+	}
+	for _, opt := range opts {
+		opt(w)
+	}
+	if w.maxBackoff < w.minBackoff {
+		w.maxBackoff = w.minBackoff
+	}
+	w.state.Policy = w.policy.String()
+	return w
+}
+
+// supervise 运行并监管后台任务，错误只记录日志，不会导致引擎退出
+func (w *worker) supervise(ctx context.Context) func() error {
+	return func() error {
+		backoff := w.minBackoff
+		for {
+			start := time.Now()
+			w.update(func(s *WorkerState) {
+				s.Status = WorkerStatusRunning
+				s.StartedAt = start
+			})
+			err := w.call(ctx)
+			if ctx.Err() != nil {
+				w.finish(WorkerStatusStopped, nil)
+				return nil
+			}
+			fields := []logger.Field{
+				logger.FieldMod(errors.ModApp),
+				logger.FieldString("worker", w.name()),
+				logger.FieldString("cost", time.Since(start).String()),
+			}
+			if err != nil {
+				w.logger.Errord("worker exit", append(fields, logger.FieldErr(err))...)
+			} else {
+				w.logger.Infod("worker exit", fields...)
+			}
+			if !w.shouldRestart(err) {
+				if err != nil {
+					w.finish(WorkerStatusFailed, err)
+				} else {
+					w.finish(WorkerStatusFinished, nil)
+				}
+				return nil
+			}
+			// 运行时间超过最大退避时间，认为已恢复正常，重置退避时间
+			if time.Since(start) > w.maxBackoff {
+				backoff = w.minBackoff
+			}
+			w.update(func(s *WorkerState) {
+				s.Status = WorkerStatusBackoff
+				s.Restarts++
+				if err != nil {
+					s.LastError = err.Error()
+				}
+			})
+			timer := time.NewTimer(backoff)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				w.finish(WorkerStatusStopped, nil)
+				return nil
+			case <-timer.C:
+			}
+			if backoff *= 2; backoff > w.maxBackoff {
+				backoff = w.maxBackoff
+			}
+		}
+	}
+}
+
+// call 运行任务方法并恢复panic
+func (w *worker) call(ctx context.Context) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+			w.logger.Errord("worker panic", logger.FieldMod(errors.ModApp), logger.FieldString("worker", w.name()), logger.FieldAny("stack", string(debug.Stack())))
+		}
+	}()
+	return w.fn(ctx)
+}
+
+// shouldRestart 根据重启策略和重启次数判断是否需要重启
+func (w *worker) shouldRestart(err error) bool {
+	switch w.policy {
+	case RestartAlways:
+	case RestartOnFailure:
+		if err == nil {
+			return false
+		}
+	default:
+		return false
+	}
+	if w.maxRestarts > 0 && w.State().Restarts >= w.maxRestarts {
+		return false
+	}
+	return true
+}
+
+// finish 设置最终状态
+func (w *worker) finish(status string, err error) {
+	w.update(func(s *WorkerState) {
+		s.Status = status
+		s.StoppedAt = time.Now()
+		if err != nil {
+			s.LastError = err.Error()
+		}
+	})
+}
+
+// update 更新状态
+func (w *worker) update(fn func(s *WorkerState)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	fn(&w.state)
+}
+
+// name 任务名称
+func (w *worker) name() string {
+	return w.state.Name
+}
+
+// State 获取状态快照
+func (w *worker) State() WorkerState {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.state
+}
+
+// Worker 添加受监管的后台任务，在Run之后添加的任务立即启动
+func (eng *Engine) Worker(name string, fn WorkerFunc, opts ...WorkerOption) error {
+	eng.initialize()
+	w := newWorker(name, fn, eng.logger, opts...)
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	if eng.stopping {
+		return errors.New(errors.CodeAddWorkerErrorStopping, errors.MsgAddWorkerErrorStopping)
+	}
+	for _, exist := range eng.workers {
+		if exist.name() == name {
+			return errors.New(errors.CodeAddWorkerErrorDuplicate, errors.MsgAddWorkerErrorDuplicate)
+		}
+	}
+	eng.workers = append(eng.workers, w)
+	if eng.workerStarted {
+		eng.cycle.Run(w.supervise(eng.ctx))
+	}
+	return nil
+}
+
+// Workers 获取所有后台任务的状态
+func (eng *Engine) Workers() []WorkerState {
+	eng.rw.RLock()
+	defer eng.rw.RUnlock()
+	res := make([]WorkerState, 0, len(eng.workers))
+	for _, w := range eng.workers {
+		res = append(res, w.State())
+	}
+	return res
+}
+
+// startWorkers 启动所有后台任务
+func (eng *Engine) startWorkers() {
+	eng.rw.Lock()
+	defer eng.rw.Unlock()
+	eng.workerStarted = true
+	for _, w := range eng.workers {
+		eng.logger.Infod("start worker", logger.FieldMod(errors.ModApp), logger.FieldString("worker", w.name()), logger.FieldString("policy", w.policy.String()))
+		eng.cycle.Run(w.supervise(eng.ctx))
+	}
+}
@@ -0,0 +1,79 @@
+//    Copyright 2022. Go-Ceres
+//    Author https://github.com/go-ceres/go-ceres
+//
+//    Licensed under the Apache License, Version 2.0 (the "License");
+//    you may not use this file except in compliance with the License.
+//    You may obtain a copy of the License at
+//
+//        http://www.apache.org/licenses/LICENSE-2.0
+//
+//    Unless required by applicable law or agreed to in writing, software
+//    distributed under the License is distributed on an "AS IS" BASIS,
+//    WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//    See the License for the specific language governing permissions and
+//    limitations under the License.
+
+package ceres
+
+import (
+	"context"
+	"errors"
+	"github.com/go-ceres/go-ceres/logger"
+	"testing"
+	"time"
+)
+
+func TestWorkerRestartOnFailure(t *testing.T) {
+	calls := 0
+	w := newWorker("test", func(ctx context.Context) error {
+		calls++
+		if calls == 2 {
+			panic("boom")
+		}
+		return errors.New("failed")
+	}, logger.FrameLogger, WorkerBackoff(time.Millisecond, 4*time.Millisecond), WorkerMaxRestarts(3))
+	if err := w.supervise(context.Background())(); err != nil {
+		t.Fatal(err)
+	}
+	state := w.State()
+	if calls != 4 || state.Restarts != 3 {
+		t.Fatalf("calls = %d, restarts = %d", calls, state.Restarts)
+	}
+	if state.Status != WorkerStatusFailed || state.LastError != "failed" {
+		t.Fatalf("unexpected state %+v", state)
+	}
+}
+
+func TestWorkerRestartNever(t *testing.T) {
+	calls := 0
+	w := newWorker("test", func(ctx context.Context) error {
+		calls++
+		return nil
+	}, logger.FrameLogger, WorkerRestart(RestartNever))
+	_ = w.supervise(context.Background())()
+	if calls != 1 || w.State().Status != WorkerStatusFinished {
+		t.Fatalf("calls = %d, state = %+v", calls, w.State())
+	}
+}
+
+func TestWorkerStopOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := newWorker("test", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}, logger.FrameLogger, WorkerRestart(RestartAlways))
+	done := make(chan struct{})
+	go func() {
+		_ = w.supervise(ctx)()
+		close(done)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("worker not stopped")
+	}
+	if w.State().Status != WorkerStatusStopped {
+		t.Fatalf("unexpected state %+v", w.State())
+	}
+}